@@ -0,0 +1,290 @@
+// Package cache implements type-safe cache for embedding into Go services on top of datastore.DataStore.
+// Keys of predeclared string, integer, float and bool types are converted into keys of DataStore without reflection.
+// Keys of other types, including named types like UserId defined as int64, require WithKeyEncoder.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"strconv"
+	"time"
+)
+
+// DefaultExpireInterval is the interval between background removals of expired items.
+const DefaultExpireInterval = time.Second
+
+// Cache is a type-safe facade of datastore.DataStore for embedding into Go services.
+// Values are stored as they are and returned by type assertion, so there is no reflection on the hot path.
+// Cache is concurrency-safe.
+type Cache[K comparable, V any] struct {
+	storage *datastore.DataStore
+	keyOf   func(key K) string
+	ttl     time.Duration
+	sliding bool
+}
+
+// config contains settings applied by options.
+type config struct {
+	ttl            time.Duration
+	sliding        bool
+	expireInterval time.Duration
+	keyEncoder     interface{}
+	storeOptions   []datastore.Option
+}
+
+// Option configures Cache.
+type Option func(*config)
+
+// WithTtl sets TTL of items saved by Set and GetOrSet. Zero TTL (default) means that items never expire.
+func WithTtl(ttl time.Duration) Option {
+	return func(c *config) {
+		c.ttl = ttl
+	}
+}
+
+// WithSliding makes expiration of items sliding: DeathTime of item is pushed forward by its TTL each time it is read.
+func WithSliding() Option {
+	return func(c *config) {
+		c.sliding = true
+	}
+}
+
+// WithMaxItems limits amount of items, items chosen by eviction policy are evicted above it.
+func WithMaxItems(maxItems int) Option {
+	return func(c *config) {
+		c.storeOptions = append(c.storeOptions, datastore.WithMaxItems(maxItems))
+	}
+}
+
+// WithMaxMemory limits estimated memory usage in bytes, items chosen by eviction policy are evicted above it.
+// Size of values of type V is returned by sizeOf, as their memory could not be estimated by DataStore.
+func WithMaxMemory[V any](maxMemory int64, sizeOf func(value V) int64) Option {
+	return func(c *config) {
+		c.storeOptions = append(c.storeOptions, datastore.WithMaxMemory(maxMemory), datastore.WithItemSize(itemSize(sizeOf)))
+	}
+}
+
+// WithEvictionPolicy sets policy which chooses items to evict, LRU policy is used by default.
+func WithEvictionPolicy(policy datastore.EvictionPolicy) Option {
+	return func(c *config) {
+		c.storeOptions = append(c.storeOptions, datastore.WithEvictionPolicy(policy))
+	}
+}
+
+// WithShards sets amount of shards of the cache without size limits.
+func WithShards(shards int) Option {
+	return func(c *config) {
+		c.storeOptions = append(c.storeOptions, datastore.WithShards(shards))
+	}
+}
+
+// WithExpireInterval sets interval between background removals of expired items, not positive value disables them.
+// Expired items are never returned anyway, but without background removal they occupy memory until accessed.
+func WithExpireInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.expireInterval = interval
+	}
+}
+
+// WithLoadErrorTtl sets the time during which error of loader is returned by GetOrLoad without new load.
+func WithLoadErrorTtl(ttl time.Duration) Option {
+	return func(c *config) {
+		c.storeOptions = append(c.storeOptions, datastore.WithLoadErrorTtl(ttl))
+	}
+}
+
+// WithKeyEncoder sets function converting keys into keys of DataStore. It is required for keys of types other than
+// predeclared string, integer, float and bool types. Different keys must be converted into different strings.
+func WithKeyEncoder[K comparable](encode func(key K) string) Option {
+	return func(c *config) {
+		c.keyEncoder = encode
+	}
+}
+
+// NewCache creates Cache and starts background removal of expired items, Close stops it.
+// It panics when keys of type K require WithKeyEncoder, but it is not provided or it accepts keys of another type.
+func NewCache[K comparable, V any](options ...Option) *Cache[K, V] {
+	c := &config{expireInterval: DefaultExpireInterval}
+	for _, option := range options {
+		option(c)
+	}
+	keyOf, ok := c.keyEncoder.(func(key K) string)
+	if c.keyEncoder != nil && !ok {
+		panic(fmt.Sprintf("cache: key encoder %T does not accept keys of type %T", c.keyEncoder, *new(K)))
+	}
+	if !ok {
+		if _, ok := basicKeyOf(*new(K)); !ok {
+			panic(fmt.Sprintf("cache: keys of type %T require key encoder", *new(K)))
+		}
+		keyOf = func(key K) string {
+			name, _ := basicKeyOf(key)
+			return name
+		}
+	}
+	storage := datastore.NewDataStore(c.storeOptions...)
+	if c.expireInterval > 0 {
+		storage.StartExpirer(c.expireInterval, datastore.DefaultExpireBatchSize)
+	}
+	return &Cache[K, V]{storage: storage, keyOf: keyOf, ttl: c.ttl, sliding: c.sliding}
+}
+
+// DataStore returns underlying storage, for example to attach persistence or listeners to it.
+// Items saved into it directly under keys of the cache must hold values of type V.
+func (c *Cache[K, V]) DataStore() *datastore.DataStore {
+	return c.storage
+}
+
+// Get returns value stored under the key. Returns false if there is no such item or it holds value of another type.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	item, ok := c.storage.Get(c.keyOf(key))
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return valueOf[V](item.(datatype.DataType))
+}
+
+// Set saves value under the key with TTL of the cache.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.storage.Set(c.keyOf(key), c.item(value, c.ttl))
+}
+
+// SetWithTtl saves value under the key with provided TTL, zero TTL means that the item never expires.
+func (c *Cache[K, V]) SetWithTtl(key K, value V, ttl time.Duration) {
+	c.storage.Set(c.keyOf(key), c.item(value, ttl))
+}
+
+// GetOrSet atomically returns existing value stored under the key or saves provided one when there is no such item.
+// Loaded is true if existing value is returned. Item holding value of another type is replaced.
+func (c *Cache[K, V]) GetOrSet(key K, value V) (actual V, loaded bool) {
+	name := c.keyOf(key)
+	for {
+		if item, ok := c.storage.Get(name); ok {
+			current := item.(datatype.DataType)
+			if existing, ok := valueOf[V](current); ok {
+				return existing, true
+			}
+			if _, err := c.storage.CompareAndSwap(name, current.Version, c.item(value, c.ttl)); err == nil {
+				return value, false
+			}
+			continue
+		}
+		if _, ok := c.storage.SetNX(name, c.item(value, c.ttl)); ok {
+			return value, false
+		}
+	}
+}
+
+// GetOrLoad returns value stored under the key, absent value is loaded by loader and stored with TTL returned by it.
+// Concurrent calls for the same key share one call of loader, its error is cached for a short time,
+// see datastore.DataStore.GetOrLoad. Returns datastore.ErrWrongType if the item holds value of another type.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context) (V, time.Duration, error)) (V, error) {
+	item, err := c.storage.GetOrLoad(ctx, c.keyOf(key), func(ctx context.Context) (interface{}, time.Duration, error) {
+		return loader(ctx)
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	value, ok := valueOf[V](item)
+	if !ok {
+		return value, datastore.ErrWrongType
+	}
+	return value, nil
+}
+
+// Delete removes the item and returns flag was it present.
+func (c *Cache[K, V]) Delete(key K) bool {
+	return c.storage.Delete(c.keyOf(key))
+}
+
+// Contains returns flag is there an item with such key.
+func (c *Cache[K, V]) Contains(key K) bool {
+	return c.storage.Contains(c.keyOf(key))
+}
+
+// Len returns amount of not expired items.
+func (c *Cache[K, V]) Len() int {
+	return c.storage.Count()
+}
+
+// Clear removes all items.
+func (c *Cache[K, V]) Clear() {
+	c.storage.Clear()
+}
+
+// Close stops background removal of expired items. Cache remains usable after Close.
+func (c *Cache[K, V]) Close() {
+	c.storage.StopExpirer()
+}
+
+func (c *Cache[K, V]) item(value V, ttl time.Duration) datatype.DataType {
+	return datatype.DataType{Value: value, Ttl: ttl, DeathTime: datatype.DeathTimeOf(ttl), Sliding: c.sliding}
+}
+
+// itemSize returns item size function of DataStore which uses sizeOf for values of type V.
+// Items of other types, for example stored into DataStore directly, are estimated by DataStore.
+func itemSize[V any](sizeOf func(value V) int64) func(datatype.DataType) int64 {
+	return func(item datatype.DataType) int64 {
+		if value, ok := item.Value.(V); ok {
+			return datatype.DataType{}.EstimateSize() + sizeOf(value)
+		}
+		return item.EstimateSize()
+	}
+}
+
+// valueOf returns value of the item. Nil value is valid only for interface type V, which zero value is nil.
+func valueOf[V any](item datatype.DataType) (V, bool) {
+	if item.Value == nil {
+		var zero V
+		return zero, any(zero) == nil
+	}
+	value, ok := item.Value.(V)
+	return value, ok
+}
+
+// basicKeyOf converts key of predeclared type into key of DataStore. Returns false for keys of other types.
+// Zero floats are equal, so negative zero is converted as positive one.
+func basicKeyOf(key interface{}) (string, bool) {
+	switch k := key.(type) {
+	case string:
+		return k, true
+	case int:
+		return strconv.Itoa(k), true
+	case int8:
+		return strconv.FormatInt(int64(k), 10), true
+	case int16:
+		return strconv.FormatInt(int64(k), 10), true
+	case int32:
+		return strconv.FormatInt(int64(k), 10), true
+	case int64:
+		return strconv.FormatInt(k, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(k), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(k), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(k), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(k), 10), true
+	case uint64:
+		return strconv.FormatUint(k, 10), true
+	case uintptr:
+		return strconv.FormatUint(uint64(k), 10), true
+	case float32:
+		if k == 0 {
+			return "0", true
+		}
+		return strconv.FormatFloat(float64(k), 'g', -1, 32), true
+	case float64:
+		if k == 0 {
+			return "0", true
+		}
+		return strconv.FormatFloat(k, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(k), true
+	}
+	return "", false
+}
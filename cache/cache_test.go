@@ -0,0 +1,307 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+type userId int
+
+func (id userId) String() string {
+	return "user"
+}
+
+func TestCache_GetSet(t *testing.T) {
+	c := NewCache[string, user]()
+	defer c.Close()
+
+	_, ok := c.Get("ivan")
+	assert.False(t, ok)
+
+	c.Set("ivan", user{"Ivan", 27})
+	value, ok := c.Get("ivan")
+	assert.True(t, ok)
+	assert.Equal(t, user{"Ivan", 27}, value)
+	assert.True(t, c.Contains("ivan"))
+	assert.Equal(t, 1, c.Len())
+
+	assert.True(t, c.Delete("ivan"))
+	assert.False(t, c.Contains("ivan"))
+}
+
+func TestCache_keys(t *testing.T) {
+	numbers := NewCache[int, string]()
+	defer numbers.Close()
+	numbers.Set(42, "answer")
+	value, ok := numbers.Get(42)
+	assert.True(t, ok)
+	assert.Equal(t, "answer", value)
+	assert.True(t, numbers.DataStore().Contains("42"))
+
+	flags := NewCache[uint8, bool]()
+	defer flags.Close()
+	flags.Set(255, true)
+	assert.True(t, flags.DataStore().Contains("255"))
+
+	ratios := NewCache[float64, string]()
+	defer ratios.Close()
+	ratios.Set(0, "zero")
+	value, ok = ratios.Get(math.Copysign(0, -1))
+	assert.True(t, ok, "Equal floats should share an item")
+	assert.Equal(t, "zero", value)
+}
+
+func TestCache_keyEncoder(t *testing.T) {
+	assert.Panics(t, func() { NewCache[userId, user]() }, "Named key type requires key encoder")
+	assert.Panics(t, func() { NewCache[*user, int]() }, "Pointer key type requires key encoder")
+	assert.Panics(t, func() { NewCache[userId, user](WithKeyEncoder(strconv.Itoa)) }, "Key encoder should accept K")
+
+	users := NewCache[userId, user](WithKeyEncoder(func(id userId) string {
+		return strconv.Itoa(int(id))
+	}))
+	defer users.Close()
+	users.Set(1, user{"Ivan", 27})
+	users.Set(2, user{"Petr", 30})
+	assert.Equal(t, 2, users.Len(), "Different keys with the same String should not collide")
+	assert.True(t, users.DataStore().Contains("2"))
+	value, ok := users.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, user{"Ivan", 27}, value)
+}
+
+func TestCache_ttl(t *testing.T) {
+	c := NewCache[string, int](WithTtl(50*time.Millisecond), WithExpireInterval(10*time.Millisecond))
+	defer c.Close()
+
+	c.Set("short", 1)
+	c.SetWithTtl("persistent", 2, 0)
+	time.Sleep(100 * time.Millisecond)
+
+	_, ok := c.Get("short")
+	assert.False(t, ok, "Item should expire after TTL of the cache")
+	value, ok := c.Get("persistent")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, c.DataStore().Stats().Count, "Expired item should be removed by background expirer")
+}
+
+func TestCache_expireIntervalDisabled(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		c := NewCache[string, int](WithTtl(time.Millisecond), WithExpireInterval(interval))
+		c.Set("short", 1)
+		time.Sleep(20 * time.Millisecond)
+		assert.True(t, c.DataStore().Stats().Memory > 0, "Not positive interval should disable expirer")
+		c.Close()
+	}
+}
+
+func TestCache_sliding(t *testing.T) {
+	c := NewCache[string, int](WithTtl(time.Minute), WithSliding())
+	defer c.Close()
+
+	c.Set("session", 1)
+	item, _ := c.DataStore().Get("session")
+	assert.True(t, item.(datatype.DataType).Sliding)
+}
+
+func TestCache_eviction(t *testing.T) {
+	c := NewCache[int, int](WithMaxItems(2), WithEvictionPolicy(datastore.NewLfuPolicy()))
+	defer c.Close()
+
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Get(1)
+	c.Set(3, 3)
+
+	assert.Equal(t, 2, c.Len())
+	assert.True(t, c.Contains(1), "Frequently used item should be kept")
+	assert.False(t, c.Contains(2), "Least frequently used item should be evicted")
+}
+
+func TestCache_maxMemory(t *testing.T) {
+	sizeOf := func(value user) int64 {
+		return 1000 + int64(len(value.Name))
+	}
+	c := NewCache[string, user](WithMaxMemory(2500, sizeOf))
+	defer c.Close()
+
+	c.Set("ivan", user{"Ivan", 27})
+	c.Set("petr", user{"Petr", 30})
+	assert.True(t, c.DataStore().Stats().Memory > 2000, "Size of values should be returned by sizeOf")
+	c.Set("olga", user{"Olga", 25})
+	assert.Equal(t, 2, c.Len())
+	assert.False(t, c.Contains("ivan"), "Least recently used item should be evicted")
+}
+
+func TestCache_GetOrSet(t *testing.T) {
+	c := NewCache[string, int]()
+	defer c.Close()
+
+	value, loaded := c.GetOrSet("answer", 42)
+	assert.False(t, loaded)
+	assert.Equal(t, 42, value)
+
+	value, loaded = c.GetOrSet("answer", 7)
+	assert.True(t, loaded)
+	assert.Equal(t, 42, value, "Existing value should be returned")
+
+	c.DataStore().Set("text", datatype.NewString("not a number", 0))
+	_, ok := c.Get("text")
+	assert.False(t, ok, "Value of another type should be treated as absent")
+	value, loaded = c.GetOrSet("text", 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, value, "Value of another type should be replaced")
+}
+
+func TestCache_GetOrSet_concurrent(t *testing.T) {
+	c := NewCache[string, int]()
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	stored := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, loaded := c.GetOrSet("key", i); !loaded {
+				mutex.Lock()
+				stored++
+				mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, stored, "Only one value should be stored")
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := NewCache[int, user](WithLoadErrorTtl(time.Minute))
+	defer c.Close()
+	calls := 0
+	loader := func(ctx context.Context) (user, time.Duration, error) {
+		calls++
+		return user{"Ivan", 27}, time.Minute, nil
+	}
+
+	value, err := c.GetOrLoad(context.Background(), 1, loader)
+	assert.Nil(t, err)
+	assert.Equal(t, user{"Ivan", 27}, value)
+	value, err = c.GetOrLoad(context.Background(), 1, loader)
+	assert.Nil(t, err)
+	assert.Equal(t, user{"Ivan", 27}, value)
+	assert.Equal(t, 1, calls)
+
+	failure := errors.New("not found in database")
+	_, err = c.GetOrLoad(context.Background(), 2, func(ctx context.Context) (user, time.Duration, error) {
+		return user{}, 0, failure
+	})
+	assert.Equal(t, failure, err)
+	_, err = c.GetOrLoad(context.Background(), 2, loader)
+	assert.Equal(t, failure, err, "Error should be cached during load error TTL")
+
+	c.DataStore().Set("3", datatype.NewString("not a user", 0))
+	_, err = c.GetOrLoad(context.Background(), 3, loader)
+	assert.Equal(t, datastore.ErrWrongType, err)
+}
+
+func TestCache_nilValue(t *testing.T) {
+	c := NewCache[string, error]()
+	defer c.Close()
+
+	c.Set("no error", nil)
+	value, ok := c.Get("no error")
+	assert.True(t, ok)
+	assert.Nil(t, value)
+}
+
+func TestCache_Clear(t *testing.T) {
+	c := NewCache[string, int](WithShards(4))
+	defer c.Close()
+
+	c.Set("one", 1)
+	c.Set("two", 2)
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+}
+
+func ExampleNewCache() {
+	users := NewCache[string, user](WithTtl(time.Minute), WithMaxItems(10000))
+	defer users.Close()
+
+	users.Set("ivan", user{"Ivan", 27})
+	if value, ok := users.Get("ivan"); ok {
+		fmt.Println(value.Name, value.Age)
+	}
+	// Output: Ivan 27
+}
+
+func ExampleWithKeyEncoder() {
+	type orderId int64
+	orders := NewCache[orderId, string](WithKeyEncoder(func(id orderId) string {
+		return strconv.FormatInt(int64(id), 10)
+	}))
+	defer orders.Close()
+
+	orders.Set(42, "shipped")
+	fmt.Println(orders.Get(42))
+	// Output: shipped true
+}
+
+func ExampleCache_GetOrSet() {
+	counters := NewCache[string, int]()
+	defer counters.Close()
+
+	value, loaded := counters.GetOrSet("visits", 1)
+	fmt.Println(value, loaded)
+	value, loaded = counters.GetOrSet("visits", 2)
+	fmt.Println(value, loaded)
+	// Output:
+	// 1 false
+	// 1 true
+}
+
+func ExampleCache_GetOrLoad() {
+	users := NewCache[int, user](WithLoadErrorTtl(5 * time.Second))
+	defer users.Close()
+
+	value, err := users.GetOrLoad(context.Background(), 1, func(ctx context.Context) (user, time.Duration, error) {
+		// concurrent callers of GetOrLoad for the same key wait for this call instead of making their own
+		return user{"Ivan", 27}, time.Minute, nil
+	})
+	fmt.Println(value.Name, err)
+	// Output: Ivan <nil>
+}
+
+func BenchmarkCache_Get(b *testing.B) {
+	c := NewCache[string, user]()
+	defer c.Close()
+	c.Set("ivan", user{"Ivan", 27})
+
+	for n := 0; n < b.N; n++ {
+		c.Get("ivan")
+	}
+}
+
+func BenchmarkCache_Set(b *testing.B) {
+	c := NewCache[int, user]()
+	defer c.Close()
+
+	for n := 0; n < b.N; n++ {
+		c.Set(n%1000, user{"Ivan", 27})
+	}
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	json "github.com/json-iterator/go"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is used for requests when no custom http.Client or timeout is provided.
+const DefaultTimeout = 10 * time.Second
+
+// NoTtl is returned by Ttl for items which never expire.
+const NoTtl time.Duration = -1
+
+// ErrNotFound is returned when requested item is absent in cache.
+var ErrNotFound = errors.New("item not found")
+
+// StatusError is returned when server responds with unexpected HTTP status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", e.StatusCode, e.Body)
+}
+
+// Client is a typed HTTP client for go-cache web server.
+// Client is concurrency-safe.
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+
+	// timeout set by WithTimeout is applied to httpClient after all options, zero value means no override
+	timeout time.Duration
+}
+
+// Option configures Client.
+type Option func(*Client)
+
+// WithHttpClient makes Client use provided http.Client for all requests, nil means the default one.
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets timeout for each request made by Client. It overrides timeout of http.Client
+// provided by WithHttpClient regardless of the order of options, provided http.Client is not modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// NewClient creates Client for server available on provided base URL, for example "http://localhost:8000".
+func NewClient(baseUrl string, options ...Option) *Client {
+	c := &Client{
+		baseUrl:    strings.TrimRight(baseUrl, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+	return c
+}
+
+// Set saves value with provided TTL under the key and returns stored item. Zero TTL means that item never expires.
+func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (datatype.DataType, error) {
+	var item datatype.DataType
+	body, err := json.Marshal(datatype.DataType{Value: value, Ttl: ttl})
+	if err != nil {
+		return item, err
+	}
+
+	err = c.do(ctx, http.MethodPost, c.itemUrl(key), body, http.StatusCreated, &item)
+	return item, err
+}
+
+// SetSliding saves value with sliding expiration under the key and returns stored item.
+// DeathTime of such item is pushed forward by TTL each time the item is read.
+func (c *Client) SetSliding(ctx context.Context, key string, value interface{}, ttl time.Duration) (datatype.DataType, error) {
+	var item datatype.DataType
+	body, err := json.Marshal(datatype.DataType{Value: value, Ttl: ttl, Sliding: true})
+	if err != nil {
+		return item, err
+	}
+
+	err = c.do(ctx, http.MethodPost, c.itemUrl(key), body, http.StatusCreated, &item)
+	return item, err
+}
+
+// Get returns item stored under the key or ErrNotFound.
+func (c *Client) Get(ctx context.Context, key string) (datatype.DataType, error) {
+	var item datatype.DataType
+	err := c.do(ctx, http.MethodGet, c.itemUrl(key), nil, http.StatusOK, &item)
+	return item, err
+}
+
+// Delete removes item stored under the key. Returns ErrNotFound if there is no such item.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return c.do(ctx, http.MethodDelete, c.itemUrl(key), nil, http.StatusNoContent, nil)
+}
+
+// Keys returns all keys stored in cache.
+func (c *Client) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := c.do(ctx, http.MethodGet, c.baseUrl+"/items/keys", nil, http.StatusOK, &keys)
+	return keys, err
+}
+
+// Clear removes all items from cache.
+func (c *Client) Clear(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, c.baseUrl+"/items/keys", nil, http.StatusNoContent, nil)
+}
+
+// Ttl returns remaining time to live of the item or NoTtl if it never expires.
+// Returns ErrNotFound if there is no such item.
+func (c *Client) Ttl(ctx context.Context, key string) (time.Duration, error) {
+	var result struct {
+		Ttl time.Duration `json:"ttl"`
+	}
+	err := c.do(ctx, http.MethodGet, c.itemUrl(key)+"/ttl", nil, http.StatusOK, &result)
+	return result.Ttl, err
+}
+
+// Expire sets new TTL of the item and returns updated item.
+// Not positive TTL removes the item, zero DataType is returned in this case.
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) (datatype.DataType, error) {
+	return c.updateTtl(ctx, key, map[string]interface{}{"ttl": ttl})
+}
+
+// ExpireAt sets new DeathTime of the item and returns updated item.
+// DeathTime in the past removes the item, zero DataType is returned in this case.
+func (c *Client) ExpireAt(ctx context.Context, key string, deathTime time.Time) (datatype.DataType, error) {
+	return c.updateTtl(ctx, key, map[string]interface{}{"deathTime": deathTime})
+}
+
+func (c *Client) updateTtl(ctx context.Context, key string, request map[string]interface{}) (datatype.DataType, error) {
+	var item datatype.DataType
+	body, err := json.Marshal(request)
+	if err != nil {
+		return item, err
+	}
+
+	err = c.do(ctx, http.MethodPut, c.itemUrl(key)+"/ttl", body, http.StatusOK, &item)
+	return item, err
+}
+
+// Persist removes TTL of the item, so it never expires, and returns updated item.
+func (c *Client) Persist(ctx context.Context, key string) (datatype.DataType, error) {
+	var item datatype.DataType
+	err := c.do(ctx, http.MethodDelete, c.itemUrl(key)+"/ttl", nil, http.StatusOK, &item)
+	return item, err
+}
+
+// IncrBy atomically increments integer value of the item by delta and returns new value.
+// Absent item is created with provided TTL, zero TTL means that it never expires. TTL of existing item is kept.
+func (c *Client) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	query := url.Values{"by": {strconv.FormatInt(delta, 10)}}
+	if ttl > 0 {
+		query.Set("ttl", ttl.String())
+	}
+	var value int64
+	err := c.do(ctx, http.MethodPost, c.itemUrl(key)+"/incr?"+query.Encode(), nil, http.StatusOK, &value)
+	return value, err
+}
+
+func (c *Client) itemUrl(key string) string {
+	return c.baseUrl + "/items/" + url.PathEscape(key)
+}
+
+// do performs request and decodes response body into result when expected status code received.
+// 204 status code is also treated as success, result is left untouched in this case.
+func (c *Client) do(ctx context.Context, method, requestUrl string, body []byte, expectedStatus int, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequest(method, requestUrl, reader)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case expectedStatus, http.StatusNoContent:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		responseBody, _ := ioutil.ReadAll(response.Body)
+		return &StatusError{StatusCode: response.StatusCode, Body: string(responseBody)}
+	}
+
+	if result == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newStubServer starts server which mimics routes of go-cache web server.
+func newStubServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/keys", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`["name","weight"]`))
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/items/keys", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}", func(writer http.ResponseWriter, request *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(request.Body).Decode(&body)
+		writer.WriteHeader(http.StatusCreated)
+		writer.Write([]byte(fmt.Sprintf(`{"value": "Ivan", "ttl": 60000000000, "sliding": %v}`, body["sliding"] == true)))
+	}).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}", func(writer http.ResponseWriter, request *http.Request) {
+		switch mux.Vars(request)["key"] {
+		case "name":
+			writer.Write([]byte(`{"value": "Ivan", "ttl": 60000000000}`))
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "broken":
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("boom"))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}", func(writer http.ResponseWriter, request *http.Request) {
+		if mux.Vars(request)["key"] != "name" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/ttl", func(writer http.ResponseWriter, request *http.Request) {
+		switch mux.Vars(request)["key"] {
+		case "name":
+			writer.Write([]byte(`{"ttl": 60000000000}`))
+		case "city":
+			writer.Write([]byte(`{"ttl": -1}`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/ttl", func(writer http.ResponseWriter, request *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(request.Body).Decode(&body)
+		switch {
+		case mux.Vars(request)["key"] != "name":
+			writer.WriteHeader(http.StatusNotFound)
+		case body["ttl"] == -1.0:
+			writer.WriteHeader(http.StatusNoContent)
+		case body["ttl"] != nil:
+			writer.Write([]byte(fmt.Sprintf(`{"value": "Ivan", "ttl": %.0f}`, body["ttl"])))
+		default:
+			writer.Write([]byte(fmt.Sprintf(`{"value": "Ivan", "deathTime": "%v"}`, body["deathTime"])))
+		}
+	}).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/ttl", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"value": "Ivan", "ttl": 0}`))
+	}).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/incr", func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		if ttl := query.Get("ttl"); ttl != "" && ttl != "1m0s" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.Write([]byte(query.Get("by")))
+	}).Methods(http.MethodPost)
+	return httptest.NewServer(router)
+}
+
+func TestClient_Set(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	item, err := client.Set(context.Background(), "name", "Ivan", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, "Ivan", item.Value)
+	assert.Equal(t, time.Minute, item.Ttl)
+}
+
+func TestClient_SetSliding(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	item, err := client.SetSliding(context.Background(), "name", "Ivan", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, true, item.Sliding)
+}
+
+func TestClient_Get(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	item, err := client.Get(context.Background(), "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "Ivan", item.Value)
+
+	_, err = client.Get(context.Background(), "absent")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = client.Get(context.Background(), "broken")
+	statusError, ok := err.(*StatusError)
+	assert.Equal(t, true, ok, "StatusError expected")
+	assert.Equal(t, http.StatusInternalServerError, statusError.StatusCode)
+	assert.Equal(t, "boom", statusError.Body)
+}
+
+func TestClient_Delete(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	assert.Nil(t, client.Delete(context.Background(), "name"))
+	assert.Equal(t, ErrNotFound, client.Delete(context.Background(), "absent"))
+}
+
+func TestClient_Keys(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	keys, err := client.Keys(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "weight"}, keys)
+}
+
+func TestClient_Clear(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	assert.Nil(t, client.Clear(context.Background()))
+}
+
+func TestClient_Ttl(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	ttl, err := client.Ttl(context.Background(), "name")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, ttl)
+	ttl, err = client.Ttl(context.Background(), "city")
+	assert.Nil(t, err)
+	assert.Equal(t, NoTtl, ttl)
+	_, err = client.Ttl(context.Background(), "absent")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestClient_Expire(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	item, err := client.Expire(context.Background(), "name", time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour, item.Ttl)
+	item, err = client.Expire(context.Background(), "name", -1)
+	assert.Nil(t, err, "Removal of item is not an error")
+	assert.Equal(t, nil, item.Value)
+	_, err = client.Expire(context.Background(), "absent", time.Hour)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestClient_ExpireAt(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+	deathTime := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	item, err := client.ExpireAt(context.Background(), "name", deathTime)
+	assert.Nil(t, err)
+	assert.Equal(t, true, deathTime.Equal(item.DeathTime))
+}
+
+func TestClient_Persist(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	item, err := client.Persist(context.Background(), "name")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), item.Ttl)
+}
+
+func TestWithTimeout(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL, WithTimeout(50*time.Millisecond))
+
+	_, err := client.Get(context.Background(), "slow")
+	assert.NotNil(t, err, "Timeout error expected")
+}
+
+func TestWithTimeout_withHttpClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Minute}
+
+	client := NewClient("http://localhost:8000", WithHttpClient(httpClient), WithTimeout(time.Second))
+	assert.Equal(t, time.Second, client.httpClient.Timeout)
+	client = NewClient("http://localhost:8000", WithTimeout(time.Second), WithHttpClient(httpClient))
+	assert.Equal(t, time.Second, client.httpClient.Timeout, "Timeout should not depend on the order of options")
+	assert.Equal(t, time.Minute, httpClient.Timeout, "Provided http.Client should not be modified")
+
+	client = NewClient("http://localhost:8000", WithHttpClient(nil), WithTimeout(time.Second))
+	assert.Equal(t, time.Second, client.httpClient.Timeout)
+	client = NewClient("http://localhost:8000", WithHttpClient(nil))
+	assert.Equal(t, DefaultTimeout, client.httpClient.Timeout, "Default http.Client should be used instead of nil")
+}
+
+func TestWithHttpClient(t *testing.T) {
+	httpClient := &http.Client{}
+	client := NewClient("http://localhost:8000/", WithHttpClient(httpClient))
+
+	assert.Equal(t, httpClient, client.httpClient)
+	assert.Equal(t, "http://localhost:8000", client.baseUrl)
+}
+
+func TestClient_contextCancellation(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Get(ctx, "slow")
+	assert.NotNil(t, err, "Context deadline error expected")
+}
+
+func ExampleClient_Set() {
+	client := NewClient("http://localhost:8000")
+	item, err := client.Set(context.Background(), "name", "Ivan", time.Minute)
+	fmt.Println(item, err)
+}
+
+func ExampleClient_Get() {
+	client := NewClient("http://localhost:8000", WithTimeout(time.Second))
+	item, err := client.Get(context.Background(), "name")
+	if err == ErrNotFound {
+		fmt.Println("no such item")
+		return
+	}
+	fmt.Println(item.Value, err)
+}
+
+func ExampleClient_Keys() {
+	client := NewClient("http://localhost:8000")
+	keys, err := client.Keys(context.Background())
+	fmt.Println(keys, err)
+}
+
+func BenchmarkClient_Get(b *testing.B) {
+	server := newStubServer()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	for n := 0; n < b.N; n++ {
+		client.Get(context.Background(), "name")
+	}
+}
+
+func TestClient_IncrBy(t *testing.T) {
+	server := newStubServer()
+	defer server.Close()
+	c := NewClient(server.URL)
+
+	value, err := c.IncrBy(context.Background(), "visits", 5, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), value)
+	value, err = c.IncrBy(context.Background(), "visits", -3, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-3), value)
+}
@@ -0,0 +1,466 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/andrei-punko/go-cache/pubsub"
+	"github.com/andrei-punko/go-cache/util"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when requested key is absent in storage.
+	ErrNotFound = errors.New("ERR no such key")
+	// ErrWrongType is returned when command is applied to item with value of unsupported type.
+	ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	// ErrOutOfRange is returned when list index is out of range.
+	ErrOutOfRange = errors.New("ERR index out of range")
+	// ErrNotInteger is returned when argument or stored value is not an integer.
+	ErrNotInteger = errors.New("ERR value is not an integer or out of range")
+	// ErrNotFloat is returned when argument or stored value is not a float.
+	ErrNotFloat = errors.New("ERR value is not a valid float")
+
+	errSyntax = errors.New("ERR syntax error")
+)
+
+// Status is a simple string reply, for example "OK".
+type Status string
+
+// StatusOK is returned by commands which have nothing to return.
+const StatusOK Status = "OK"
+
+// Reply is a result of command execution. It could be one of:
+// nil (absent value), Status, error, int, int64, string, []string.
+type Reply interface{}
+
+// handler executes command with provided arguments (without command name) and returns reply.
+type handler func(e *Executor, args []string) Reply
+
+// spec describes supported command: its handler and allowed amount of arguments.
+type spec struct {
+	handler handler
+	minArgs int
+	maxArgs int // -1 means unlimited
+}
+
+var specs = map[string]spec{
+	"PING":          {ping, 0, 1},
+	"GET":           {get, 1, 1},
+	"SET":           {set, 2, 5},
+	"DEL":           {del, 1, -1},
+	"KEYS":          {keys, 0, 1},
+	"EXISTS":        {exists, 1, -1},
+	"FLUSHALL":      {flushAll, 0, 1},
+	"TTL":           {ttl, 1, 1},
+	"PERSIST":       {persist, 1, 1},
+	"EXPIRE":        {expire, 2, 2},
+	"EXPIREAT":      {expireAt, 2, 2},
+	"LPUSH":         {lPush, 2, -1},
+	"RPUSH":         {rPush, 2, -1},
+	"LPOP":          {lPop, 1, 1},
+	"RPOP":          {rPop, 1, 1},
+	"LRANGE":        {lRange, 3, 3},
+	"LTRIM":         {lTrim, 3, 3},
+	"LINDEX":        {lIndex, 2, 2},
+	"LSET":          {lSet, 3, 3},
+	"LLEN":          {lLen, 1, 1},
+	"HGET":          {hGet, 2, 2},
+	"HSET":          {hSet, 3, -1},
+	"HDEL":          {hDel, 2, -1},
+	"HKEYS":         {hKeys, 1, 1},
+	"HGETALL":       {hGetAll, 1, 1},
+	"HEXISTS":       {hExists, 2, 2},
+	"HINCRBY":       {hIncrBy, 3, 3},
+	"INCR":          {incr, 1, 1},
+	"DECR":          {decr, 1, 1},
+	"INCRBY":        {incrBy, 2, 2},
+	"INCRBYFLOAT":   {incrByFloat, 2, 2},
+	"SADD":          {sAdd, 2, -1},
+	"SREM":          {sRem, 2, -1},
+	"SISMEMBER":     {sIsMember, 2, 2},
+	"SMEMBERS":      {sMembers, 1, 1},
+	"SCARD":         {sCard, 1, 1},
+	"SUNION":        {sUnion, 1, -1},
+	"SINTER":        {sInter, 1, -1},
+	"SDIFF":         {sDiff, 1, -1},
+	"SUNIONSTORE":   {sUnionStore, 2, -1},
+	"SINTERSTORE":   {sInterStore, 2, -1},
+	"SDIFFSTORE":    {sDiffStore, 2, -1},
+	"ZADD":          {zAdd, 3, -1},
+	"ZINCRBY":       {zIncrBy, 3, 3},
+	"ZREM":          {zRem, 2, -1},
+	"ZSCORE":        {zScore, 2, 2},
+	"ZCARD":         {zCard, 1, 1},
+	"ZRANK":         {zRank, 2, 2},
+	"ZREVRANK":      {zRevRank, 2, 2},
+	"ZRANGE":        {zRange, 3, 5},
+	"ZRANGEBYSCORE": {zRangeByScore, 3, 7},
+	"SAVE":          {save, 0, 0},
+	"PUBLISH":       {publish, 2, 2},
+	"PUBSUB":        {pubSub, 1, 2},
+
+	"BGREWRITEAOF": {bgRewriteAof, 0, 0},
+}
+
+// Saver persists storage content on demand, for example into snapshot file.
+type Saver interface {
+	Save() error
+}
+
+// Rewriter compacts append-only log of storage operations.
+type Rewriter interface {
+	Rewrite() error
+}
+
+// Executor dispatches commands to DataStore. It is shared by all API protocols.
+type Executor struct {
+	storage    *datastore.DataStore
+	defaultTtl time.Duration
+	saver      Saver
+	rewriter   Rewriter
+	broker     *pubsub.Broker
+}
+
+// NewExecutor creates Executor backed by provided storage.
+// defaultTtl is used for items saved by SET command without TTL, zero value means that such items never expire.
+func NewExecutor(storage *datastore.DataStore, defaultTtl time.Duration) *Executor {
+	return &Executor{storage: storage, defaultTtl: defaultTtl}
+}
+
+// SetSaver sets Saver used by SAVE command. SAVE fails when no Saver is set.
+func (e *Executor) SetSaver(saver Saver) {
+	e.saver = saver
+}
+
+// SetRewriter sets Rewriter used by BGREWRITEAOF command. BGREWRITEAOF fails when no Rewriter is set.
+func (e *Executor) SetRewriter(rewriter Rewriter) {
+	e.rewriter = rewriter
+}
+
+// SetBroker sets Broker used by PUBLISH and PUBSUB commands. They fail when no Broker is set.
+func (e *Executor) SetBroker(broker *pubsub.Broker) {
+	e.broker = broker
+}
+
+// Broker returns Broker used by PUBLISH command, it is nil when publish/subscribe is disabled.
+func (e *Executor) Broker() *pubsub.Broker {
+	return e.broker
+}
+
+// Execute runs command with name in args[0] and its arguments in the rest of args.
+func (e *Executor) Execute(args []string) Reply {
+	cmd, err := lookup(args)
+	if err != nil {
+		return err
+	}
+	return cmd.handler(e, args[1:])
+}
+
+// lookup returns spec of command with name in args[0] and checks amount of its arguments.
+func lookup(args []string) (spec, error) {
+	if len(args) == 0 {
+		return spec{}, errors.New("ERR empty command")
+	}
+	name := strings.ToUpper(args[0])
+	cmd, ok := specs[name]
+	if !ok {
+		return spec{}, fmt.Errorf("ERR unknown command '%s'", args[0])
+	}
+	if amount := len(args) - 1; amount < cmd.minArgs || (cmd.maxArgs != -1 && amount > cmd.maxArgs) {
+		return spec{}, fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+	}
+	return cmd, nil
+}
+
+// Set saves item under the key and returns stored item with assigned version.
+func (e *Executor) Set(key string, item datatype.DataType) datatype.DataType {
+	return e.storage.Set(key, item)
+}
+
+// Get returns item stored under the key or ErrNotFound.
+func (e *Executor) Get(key string) (datatype.DataType, error) {
+	value, ok := e.storage.Get(key)
+	if !ok {
+		return datatype.DataType{}, ErrNotFound
+	}
+	return value.(datatype.DataType), nil
+}
+
+// Delete removes item stored under the key. Returns ErrNotFound if there is no such item.
+func (e *Executor) Delete(key string) error {
+	if ok := e.storage.Delete(key); !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Keys returns all keys matching glob-style pattern.
+func (e *Executor) Keys(pattern string) []string {
+	result := []string{}
+	for _, key := range util.InterfaceListToStringList(e.storage.GetKeys()) {
+		if util.MatchPattern(pattern, key) {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// Ttl returns remaining time to live of the item or datastore.NoTtl if it never expires.
+// Returns ErrNotFound if there is no such item.
+func (e *Executor) Ttl(key string) (time.Duration, error) {
+	ttl, ok := e.storage.Ttl(key)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return ttl, nil
+}
+
+// Expire sets new TTL of the item and returns updated item. Not positive TTL removes the item.
+// Returns ErrNotFound if there is no such item.
+func (e *Executor) Expire(key string, ttl time.Duration) (datatype.DataType, error) {
+	item, ok := e.storage.Expire(key, ttl)
+	if !ok {
+		return item, ErrNotFound
+	}
+	return item, nil
+}
+
+// ExpireAt sets new DeathTime of the item and returns updated item. DeathTime in the past removes the item.
+// Returns ErrNotFound if there is no such item.
+func (e *Executor) ExpireAt(key string, deathTime time.Time) (datatype.DataType, error) {
+	item, ok := e.storage.ExpireAt(key, deathTime)
+	if !ok {
+		return item, ErrNotFound
+	}
+	return item, nil
+}
+
+// Persist removes TTL of the item and returns it. Returns ErrNotFound if there is no such item.
+func (e *Executor) Persist(key string) (datatype.DataType, error) {
+	e.storage.Persist(key)
+	return e.Get(key)
+}
+
+// Clear removes all items from storage.
+func (e *Executor) Clear() {
+	e.storage.Clear()
+}
+
+func ping(e *Executor, args []string) Reply {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return Status("PONG")
+}
+
+func get(e *Executor, args []string) Reply {
+	item, err := e.Get(args[0])
+	if err != nil {
+		return nil
+	}
+	value, ok := formatValue(item.Value)
+	if !ok {
+		return ErrWrongType
+	}
+	return value
+}
+
+// formatValue converts scalar value into string, it returns false for values of other types.
+func formatValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	default:
+		return "", false
+	}
+}
+
+// storageError converts error returned by DataStore into reply, nil is returned as it is.
+func storageError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case datastore.ErrWrongType:
+		return ErrWrongType
+	case datastore.ErrNotFound:
+		return ErrNotFound
+	case datastore.ErrOutOfRange:
+		return ErrOutOfRange
+	case datastore.ErrNotInteger:
+		return ErrNotInteger
+	case datastore.ErrNotFloat:
+		return ErrNotFloat
+	default:
+		return fmt.Errorf("ERR %v", err)
+	}
+}
+
+// set supports both Redis syntax "SET key value [EX seconds|PX milliseconds] [NX|XX]"
+// and short one with TTL in Go duration format: "SET key value [ttl] [NX|XX]", for example "SET name Ivan 60s".
+// With NX the item is saved only if the key is absent, with XX only if it is present, nil is returned otherwise.
+func set(e *Executor, args []string) Reply {
+	ttl := e.defaultTtl
+	hasTtl := false
+	mode := ""
+	options := args[2:]
+	for i := 0; i < len(options); i++ {
+		switch option := strings.ToUpper(options[i]); {
+		case option == "NX" || option == "XX":
+			if mode != "" {
+				return errSyntax
+			}
+			mode = option
+		case (option == "EX" || option == "PX") && !hasTtl && i+1 < len(options):
+			amount, err := strconv.ParseInt(options[i+1], 10, 64)
+			if err != nil {
+				return ErrNotInteger
+			}
+			ttl = time.Duration(amount) * time.Second
+			if option == "PX" {
+				ttl = time.Duration(amount) * time.Millisecond
+			}
+			hasTtl = true
+			i++
+		case !hasTtl:
+			duration, err := time.ParseDuration(options[i])
+			if err != nil {
+				return errSyntax
+			}
+			ttl = duration
+			hasTtl = true
+		default:
+			return errSyntax
+		}
+	}
+	if ttl < 0 || (ttl == 0 && hasTtl) {
+		return errors.New("ERR invalid expire time in 'set' command")
+	}
+
+	item := datatype.NewString(args[1], ttl)
+	ok := true
+	switch mode {
+	case "NX":
+		_, ok = e.storage.SetNX(args[0], item)
+	case "XX":
+		_, ok = e.storage.SetXX(args[0], item)
+	default:
+		e.Set(args[0], item)
+	}
+	if !ok {
+		return nil
+	}
+	return StatusOK
+}
+
+func del(e *Executor, args []string) Reply {
+	count := 0
+	for _, result := range e.storage.BatchDelete(util.StringListToInterfaceList(args)) {
+		if result {
+			count++
+		}
+	}
+	return count
+}
+
+func keys(e *Executor, args []string) Reply {
+	pattern := "*"
+	if len(args) == 1 {
+		pattern = args[0]
+	}
+	return e.Keys(pattern)
+}
+
+func exists(e *Executor, args []string) Reply {
+	count := 0
+	for _, key := range args {
+		if e.storage.Contains(key) {
+			count++
+		}
+	}
+	return count
+}
+
+func flushAll(e *Executor, args []string) Reply {
+	e.Clear()
+	return StatusOK
+}
+
+// ttl returns remaining time to live of a key in seconds, -1 if the key has no TTL or -2 if the key does not exist.
+func ttl(e *Executor, args []string) Reply {
+	remaining, err := e.Ttl(args[0])
+	if err != nil {
+		return -2
+	}
+	if remaining == datastore.NoTtl {
+		return -1
+	}
+	return int64((remaining + time.Second/2) / time.Second)
+}
+
+// expire sets TTL of a key in seconds. Returns 1 if TTL was set and 0 if the key does not exist.
+func expire(e *Executor, args []string) Reply {
+	seconds, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return ErrNotInteger
+	}
+	if _, err := e.Expire(args[0], time.Duration(seconds)*time.Second); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// expireAt sets DeathTime of a key as Unix timestamp in seconds. Returns 1 if it was set and 0 if the key does not exist.
+func expireAt(e *Executor, args []string) Reply {
+	timestamp, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return ErrNotInteger
+	}
+	if _, err := e.ExpireAt(args[0], time.Unix(timestamp, 0)); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// persist removes TTL of a key. Returns 1 if TTL was removed and 0 if the key does not exist or has no TTL.
+func persist(e *Executor, args []string) Reply {
+	if e.storage.Persist(args[0]) {
+		return 1
+	}
+	return 0
+}
+
+func save(e *Executor, args []string) Reply {
+	if e.saver == nil {
+		return errors.New("ERR persistence is disabled")
+	}
+	if err := e.saver.Save(); err != nil {
+		return fmt.Errorf("ERR %v", err)
+	}
+	return StatusOK
+}
+
+// bgRewriteAof starts append-only log rewrite in background.
+func bgRewriteAof(e *Executor, args []string) Reply {
+	if e.rewriter == nil {
+		return errors.New("ERR append-only log is disabled")
+	}
+	go func() {
+		if err := e.rewriter.Rewrite(); err != nil {
+			log.Printf("Error during append-only log rewrite: %v", err)
+		}
+	}()
+	return Status("Background append only file rewriting started")
+}
@@ -0,0 +1,259 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, Status("PONG"), executor.Execute([]string{"ping"}))
+	assert.Equal(t, "hello", executor.Execute([]string{"PING", "hello"}))
+	assert.Equal(t, errors.New("ERR unknown command 'foo'"), executor.Execute([]string{"foo"}))
+	assert.Equal(t, errors.New("ERR wrong number of arguments for 'get' command"), executor.Execute([]string{"GET"}))
+	assert.Equal(t, errors.New("ERR empty command"), executor.Execute([]string{}))
+}
+
+func TestExecutor_Execute_set(t *testing.T) {
+	storage := datastore.NewDataStore()
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "name", "Ivan"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "weight", "82.5kg", "90s"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "age", "27", "EX", "10"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "city", "Minsk", "px", "1500"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"SET", "city", "Minsk", "soon"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"SET", "city", "Minsk", "ZZ", "5"}))
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"SET", "city", "Minsk", "EX", "five"}))
+
+	expectedTtls := map[string]time.Duration{
+		"name":   time.Minute,
+		"weight": 90 * time.Second,
+		"age":    10 * time.Second,
+		"city":   1500 * time.Millisecond,
+	}
+	for key, expectedTtl := range expectedTtls {
+		item, err := executor.Get(key)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedTtl, item.Ttl, "Wrong TTL of "+key)
+	}
+}
+
+func TestExecutor_Execute_get(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("cards", datatype.NewList([]interface{}{"VISA"}, time.Minute))
+	storage.Set("weight", datatype.DataType{Value: 82.5, Ttl: time.Minute, DeathTime: time.Now().Add(time.Minute)})
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, "Ivan", executor.Execute([]string{"GET", "name"}))
+	assert.Equal(t, "82.5", executor.Execute([]string{"GET", "weight"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"GET", "cards"}))
+	assert.Equal(t, nil, executor.Execute([]string{"GET", "absent"}))
+}
+
+func TestExecutor_Execute_delAndExists(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, 2, executor.Execute([]string{"EXISTS", "name", "weight", "age"}))
+	assert.Equal(t, 1, executor.Execute([]string{"DEL", "name", "age"}))
+	assert.Equal(t, 1, executor.Execute([]string{"EXISTS", "name", "weight"}))
+}
+
+func TestExecutor_Execute_keysAndFlushAll(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("user:1", datatype.NewString("Ivan", time.Minute))
+	storage.Set("user:2", datatype.NewString("Petr", 2*time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", 3*time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, []string{"user:1", "user:2"}, executor.Execute([]string{"KEYS", "user:*"}))
+	assert.Equal(t, []string{"user:1", "user:2", "weight"}, executor.Execute([]string{"KEYS"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"FLUSHALL"}))
+	assert.Equal(t, 0, storage.Count())
+}
+
+func TestExecutor_Execute_ttl(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, int64(60), executor.Execute([]string{"TTL", "name"}))
+	assert.Equal(t, -2, executor.Execute([]string{"TTL", "absent"}))
+}
+
+func TestExecutor_Execute_persist(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, 1, executor.Execute([]string{"PERSIST", "name"}))
+	assert.Equal(t, -1, executor.Execute([]string{"TTL", "name"}))
+	assert.Equal(t, 0, executor.Execute([]string{"PERSIST", "name"}), "Item has no TTL already")
+	assert.Equal(t, 0, executor.Execute([]string{"PERSIST", "absent"}))
+	assert.Equal(t, "Ivan", executor.Execute([]string{"GET", "name"}))
+}
+
+func TestExecutor_Execute_expire(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	storage.Set("city", datatype.NewString("Minsk", 0))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, 1, executor.Execute([]string{"EXPIRE", "name", "100"}))
+	assert.Equal(t, int64(100), executor.Execute([]string{"TTL", "name"}))
+	assert.Equal(t, 1, executor.Execute([]string{"EXPIRE", "city", "10"}))
+	assert.Equal(t, int64(10), executor.Execute([]string{"TTL", "city"}))
+	assert.Equal(t, 1, executor.Execute([]string{"EXPIRE", "weight", "0"}))
+	assert.Equal(t, -2, executor.Execute([]string{"TTL", "weight"}), "Not positive TTL removes the key")
+	assert.Equal(t, 0, executor.Execute([]string{"EXPIRE", "absent", "10"}))
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"EXPIRE", "name", "soon"}))
+}
+
+func TestExecutor_Execute_expireAt(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	timestamp := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	assert.Equal(t, 1, executor.Execute([]string{"EXPIREAT", "name", timestamp}))
+	assert.Equal(t, true, executor.Execute([]string{"TTL", "name"}).(int64) > 3590)
+	assert.Equal(t, 1, executor.Execute([]string{"EXPIREAT", "weight", "1000000000"}))
+	assert.Equal(t, 0, executor.Execute([]string{"EXISTS", "weight"}), "DeathTime in the past removes the key")
+	assert.Equal(t, 0, executor.Execute([]string{"EXPIREAT", "absent", timestamp}))
+}
+
+func TestExecutor_Persist(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	item, err := executor.Persist("name")
+	assert.Nil(t, err)
+	assert.Equal(t, true, item.IsPersistent())
+	item, err = executor.Persist("name")
+	assert.Nil(t, err, "Persisting of item without TTL is not an error")
+	_, err = executor.Persist("absent")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestExecutor_Execute_setWithoutDefaultTtl(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), 0)
+
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "name", "Ivan"}))
+	assert.Equal(t, -1, executor.Execute([]string{"TTL", "name"}), "Zero default TTL means no expiration")
+	assert.Equal(t, errors.New("ERR invalid expire time in 'set' command"), executor.Execute([]string{"SET", "name", "Ivan", "EX", "0"}))
+}
+
+func TestExecutor_Execute_setConditional(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "lock", "owner-1", "NX"}))
+	assert.Equal(t, nil, executor.Execute([]string{"SET", "lock", "owner-2", "EX", "10", "nx"}), "Present key is not overwritten")
+	assert.Equal(t, "owner-1", executor.Execute([]string{"GET", "lock"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "lock", "owner-3", "30s", "XX"}))
+	assert.Equal(t, "owner-3", executor.Execute([]string{"GET", "lock"}))
+	assert.Equal(t, nil, executor.Execute([]string{"SET", "city", "Minsk", "XX"}), "Absent key is not created")
+	assert.Equal(t, nil, executor.Execute([]string{"GET", "city"}))
+
+	item, _ := executor.Get("lock")
+	assert.Equal(t, 30*time.Second, item.Ttl)
+	assert.Equal(t, errSyntax, executor.Execute([]string{"SET", "lock", "owner-4", "NX", "XX"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"SET", "lock", "owner-4", "EX", "10", "30s"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"SET", "lock", "owner-4", "EX"}))
+}
+
+type stubSaver struct {
+	err   error
+	calls int
+}
+
+func (s *stubSaver) Save() error {
+	s.calls++
+	return s.err
+}
+
+func TestExecutor_Execute_save(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	assert.Equal(t, errors.New("ERR persistence is disabled"), executor.Execute([]string{"SAVE"}))
+
+	saver := &stubSaver{}
+	executor.SetSaver(saver)
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SAVE"}))
+	assert.Equal(t, 1, saver.calls)
+
+	saver.err = errors.New("disk is full")
+	assert.Equal(t, errors.New("ERR disk is full"), executor.Execute([]string{"SAVE"}))
+}
+
+type stubRewriter struct {
+	calls chan struct{}
+}
+
+func (r *stubRewriter) Rewrite() error {
+	r.calls <- struct{}{}
+	return nil
+}
+
+func TestExecutor_Execute_bgRewriteAof(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	assert.Equal(t, errors.New("ERR append-only log is disabled"), executor.Execute([]string{"BGREWRITEAOF"}))
+
+	rewriter := &stubRewriter{make(chan struct{}, 1)}
+	executor.SetRewriter(rewriter)
+	assert.Equal(t, Status("Background append only file rewriting started"), executor.Execute([]string{"BGREWRITEAOF"}))
+	select {
+	case <-rewriter.calls:
+	case <-time.After(time.Second):
+		t.Error("Rewrite should be called")
+	}
+}
+
+func TestExecutor_Get(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	item, err := executor.Get("name")
+	assert.Nil(t, err)
+	assert.Equal(t, "Ivan", item.Value)
+
+	_, err = executor.Get("absent")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestExecutor_Delete(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Nil(t, executor.Delete("name"))
+	assert.Equal(t, ErrNotFound, executor.Delete("name"))
+}
+
+func ExampleExecutor_Execute() {
+	executor := NewExecutor(datastore.NewDataStore(), time.Hour)
+	executor.Execute([]string{"SET", "name", "Ivan", "60s"})
+	fmt.Println(executor.Execute([]string{"GET", "name"}))
+}
+
+func BenchmarkExecutor_Execute(b *testing.B) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.Execute([]string{"SET", "name", "Ivan"})
+
+	for n := 0; n < b.N; n++ {
+		executor.Execute([]string{"GET", "name"})
+	}
+}
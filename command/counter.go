@@ -0,0 +1,59 @@
+package command
+
+import (
+	"strconv"
+	"time"
+)
+
+// Counters created by increment commands get default TTL of the Executor like items saved by SET.
+
+// IncrBy increments integer value of the item by delta and returns new value.
+// Absent item is created with provided TTL, zero TTL means that it never expires.
+func (e *Executor) IncrBy(key string, delta int64, ttl time.Duration) (int64, error) {
+	value, err := e.storage.IncrBy(key, delta, ttl)
+	return value, storageError(err)
+}
+
+// IncrByFloat increments numeric value of the item by delta and returns new value.
+// Absent item is created with provided TTL, zero TTL means that it never expires.
+func (e *Executor) IncrByFloat(key string, delta float64, ttl time.Duration) (float64, error) {
+	value, err := e.storage.IncrByFloat(key, delta, ttl)
+	return value, storageError(err)
+}
+
+func incr(e *Executor, args []string) Reply {
+	return e.incrBy(args[0], 1)
+}
+
+func decr(e *Executor, args []string) Reply {
+	return e.incrBy(args[0], -1)
+}
+
+func incrBy(e *Executor, args []string) Reply {
+	delta, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return ErrNotInteger
+	}
+	return e.incrBy(args[0], delta)
+}
+
+// incrByFloat replies with new value formatted as string like Redis does.
+func incrByFloat(e *Executor, args []string) Reply {
+	delta, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return ErrNotFloat
+	}
+	value, err := e.IncrByFloat(args[0], delta, e.defaultTtl)
+	if err != nil {
+		return err
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func (e *Executor) incrBy(key string, delta int64) Reply {
+	value, err := e.IncrBy(key, delta, e.defaultTtl)
+	if err != nil {
+		return err
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute_incrAndDecr(t *testing.T) {
+	storage := datastore.NewDataStore()
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, int64(1), executor.Execute([]string{"INCR", "visits"}))
+	assert.Equal(t, int64(11), executor.Execute([]string{"INCRBY", "visits", "10"}))
+	assert.Equal(t, int64(10), executor.Execute([]string{"DECR", "visits"}))
+	assert.Equal(t, "10", executor.Execute([]string{"GET", "visits"}))
+	ttl, _ := storage.Ttl("visits")
+	assert.Equal(t, true, ttl > 59*time.Second, "Default TTL is used for created counter")
+
+	assert.Equal(t, StatusOK, executor.Execute([]string{"SET", "limit", "100"}))
+	assert.Equal(t, int64(99), executor.Execute([]string{"DECR", "limit"}))
+	assert.Equal(t, "99", executor.Execute([]string{"GET", "limit"}))
+}
+
+func TestExecutor_Execute_incrByFloat(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, "10.5", executor.Execute([]string{"INCRBYFLOAT", "price", "10.5"}))
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"INCR", "price"}), "Float value is not an integer")
+	assert.Equal(t, "10", executor.Execute([]string{"INCRBYFLOAT", "price", "-0.5"}))
+	assert.Equal(t, int64(11), executor.Execute([]string{"INCR", "price"}))
+	assert.Equal(t, ErrNotFloat, executor.Execute([]string{"INCRBYFLOAT", "price", "cheap"}))
+}
+
+func TestExecutor_Execute_incrErrors(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("phones", datatype.NewList([]interface{}{"Nokia"}, time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"INCR", "name"}))
+	assert.Equal(t, ErrNotFloat, executor.Execute([]string{"INCRBYFLOAT", "name", "1"}))
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"INCRBY", "visits", "many"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"INCR", "phones"}))
+}
+
+func TestExecutor_IncrBy(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	value, err := executor.IncrBy("visits", 5, 0)
+	assert.Equal(t, int64(5), value)
+	assert.Nil(t, err)
+	ttl, _ := storage.Ttl("visits")
+	assert.Equal(t, datastore.NoTtl, ttl, "Zero TTL means that counter never expires")
+
+	_, err = executor.IncrBy("name", 1, 0)
+	assert.Equal(t, ErrNotInteger, err)
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// HGet returns value of the dict field. Returns false if there is no such field.
+func (e *Executor) HGet(key, field string) (interface{}, bool, error) {
+	value, ok, err := e.storage.HGet(key, field)
+	return value, ok, storageError(err)
+}
+
+// HSet sets values of the dict fields and returns amount of added fields.
+func (e *Executor) HSet(key string, fields map[string]interface{}) (int, error) {
+	added, err := e.storage.HSet(key, fields)
+	return added, storageError(err)
+}
+
+// HDel removes the dict fields and returns amount of removed fields.
+func (e *Executor) HDel(key string, fields ...string) (int, error) {
+	removed, err := e.storage.HDel(key, fields...)
+	return removed, storageError(err)
+}
+
+// HKeys returns sorted names of the dict fields.
+func (e *Executor) HKeys(key string) ([]string, error) {
+	fields, err := e.storage.HKeys(key)
+	return fields, storageError(err)
+}
+
+// HGetAll returns all fields of the dict.
+func (e *Executor) HGetAll(key string) (map[string]interface{}, error) {
+	dict, err := e.storage.HGetAll(key)
+	return dict, storageError(err)
+}
+
+// HExists returns flag is there such field in the dict.
+func (e *Executor) HExists(key, field string) (bool, error) {
+	exists, err := e.storage.HExists(key, field)
+	return exists, storageError(err)
+}
+
+// HIncrBy increments integer value of the dict field by delta and returns new value.
+func (e *Executor) HIncrBy(key, field string, delta int64) (int64, error) {
+	value, err := e.storage.HIncrBy(key, field, delta)
+	return value, storageError(err)
+}
+
+func hGet(e *Executor, args []string) Reply {
+	return popReply(e.HGet(args[0], args[1]))
+}
+
+// hSet supports multiple field-value pairs: "HSET key field value [field value ...]".
+func hSet(e *Executor, args []string) Reply {
+	pairs := args[1:]
+	if len(pairs)%2 != 0 {
+		return fmt.Errorf("ERR wrong number of arguments for 'hset' command")
+	}
+	fields := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		fields[pairs[i]] = pairs[i+1]
+	}
+	return countReply(e.HSet(args[0], fields))
+}
+
+func hDel(e *Executor, args []string) Reply {
+	return countReply(e.HDel(args[0], args[1:]...))
+}
+
+func hKeys(e *Executor, args []string) Reply {
+	return membersReply(e.HKeys(args[0]))
+}
+
+// hGetAll returns fields and their values one after another sorted by field names.
+func hGetAll(e *Executor, args []string) Reply {
+	dict, err := e.HGetAll(args[0])
+	if err != nil {
+		return err
+	}
+	fields := make([]string, 0, len(dict))
+	for field := range dict {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	result := make([]string, 0, 2*len(fields))
+	for _, field := range fields {
+		result = append(result, field, formatListValue(dict[field]))
+	}
+	return result
+}
+
+func hExists(e *Executor, args []string) Reply {
+	return flagReply(e.HExists(args[0], args[1]))
+}
+
+func hIncrBy(e *Executor, args []string) Reply {
+	delta, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return ErrNotInteger
+	}
+	value, err := e.HIncrBy(args[0], args[1], delta)
+	if err != nil {
+		return err
+	}
+	return value
+}
+
+// flagReply replies with 1 for true flag and with 0 for false one.
+func flagReply(flag bool, err error) Reply {
+	if err != nil {
+		return err
+	}
+	if flag {
+		return 1
+	}
+	return 0
+}
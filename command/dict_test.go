@@ -0,0 +1,48 @@
+package command
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute_hSetAndHGet(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, 2, executor.Execute([]string{"HSET", "marks", "Math", "9", "English", "7"}))
+	assert.Equal(t, 0, executor.Execute([]string{"HSET", "marks", "Math", "10"}))
+	assert.Equal(t, "10", executor.Execute([]string{"HGET", "marks", "Math"}))
+	assert.Equal(t, nil, executor.Execute([]string{"HGET", "marks", "Physics"}))
+	assert.Equal(t, nil, executor.Execute([]string{"HGET", "absent", "Math"}))
+	assert.Equal(t, "ERR wrong number of arguments for 'hset' command",
+		executor.Execute([]string{"HSET", "marks", "Math", "9", "English"}).(error).Error())
+}
+
+func TestExecutor_Execute_hKeysHGetAllHExistsHDel(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.Execute([]string{"HSET", "marks", "Math", "9", "English", "7"})
+
+	assert.Equal(t, []string{"English", "Math"}, executor.Execute([]string{"HKEYS", "marks"}))
+	assert.Equal(t, []string{"English", "7", "Math", "9"}, executor.Execute([]string{"HGETALL", "marks"}))
+	assert.Equal(t, 1, executor.Execute([]string{"HEXISTS", "marks", "Math"}))
+	assert.Equal(t, 1, executor.Execute([]string{"HDEL", "marks", "Math", "Physics"}))
+	assert.Equal(t, 0, executor.Execute([]string{"HEXISTS", "marks", "Math"}))
+	assert.Equal(t, 1, executor.Execute([]string{"HDEL", "marks", "English"}))
+	assert.Equal(t, []string{}, executor.Execute([]string{"HGETALL", "marks"}))
+	assert.Equal(t, []string{}, executor.Execute([]string{"HKEYS", "marks"}))
+}
+
+func TestExecutor_Execute_hIncrBy(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, int64(5), executor.Execute([]string{"HINCRBY", "counters", "visits", "5"}))
+	assert.Equal(t, int64(3), executor.Execute([]string{"HINCRBY", "counters", "visits", "-2"}))
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"HINCRBY", "counters", "visits", "many"}))
+	executor.Execute([]string{"HSET", "counters", "title", "main"})
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"HINCRBY", "counters", "title", "1"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"HGET", "name", "first"}))
+}
@@ -0,0 +1,155 @@
+package command
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/util"
+	"strconv"
+)
+
+// LPush pushes values to the head of the list and returns new length of the list.
+func (e *Executor) LPush(key string, values ...interface{}) (int, error) {
+	length, err := e.storage.LPush(key, values...)
+	return length, storageError(err)
+}
+
+// RPush pushes values to the tail of the list and returns new length of the list.
+func (e *Executor) RPush(key string, values ...interface{}) (int, error) {
+	length, err := e.storage.RPush(key, values...)
+	return length, storageError(err)
+}
+
+// LPop removes and returns value from the head of the list. Returns false if the list is absent.
+func (e *Executor) LPop(key string) (interface{}, bool, error) {
+	value, ok, err := e.storage.LPop(key)
+	return value, ok, storageError(err)
+}
+
+// RPop removes and returns value from the tail of the list. Returns false if the list is absent.
+func (e *Executor) RPop(key string) (interface{}, bool, error) {
+	value, ok, err := e.storage.RPop(key)
+	return value, ok, storageError(err)
+}
+
+// LRange returns values of the list between start and stop indexes inclusive.
+func (e *Executor) LRange(key string, start, stop int) ([]interface{}, error) {
+	values, err := e.storage.LRange(key, start, stop)
+	return values, storageError(err)
+}
+
+// LTrim leaves only values of the list between start and stop indexes inclusive.
+func (e *Executor) LTrim(key string, start, stop int) error {
+	return storageError(e.storage.LTrim(key, start, stop))
+}
+
+// LIndex returns value of the list with provided index. Returns false if there is no such value.
+func (e *Executor) LIndex(key string, index int) (interface{}, bool, error) {
+	value, ok, err := e.storage.LIndex(key, index)
+	return value, ok, storageError(err)
+}
+
+// LSet replaces value of the list with provided index.
+func (e *Executor) LSet(key string, index int, value interface{}) error {
+	return storageError(e.storage.LSet(key, index, value))
+}
+
+// LLen returns length of the list.
+func (e *Executor) LLen(key string) (int, error) {
+	length, err := e.storage.LLen(key)
+	return length, storageError(err)
+}
+
+func lPush(e *Executor, args []string) Reply {
+	return countReply(e.LPush(args[0], util.StringListToInterfaceList(args[1:])...))
+}
+
+func rPush(e *Executor, args []string) Reply {
+	return countReply(e.RPush(args[0], util.StringListToInterfaceList(args[1:])...))
+}
+
+func lPop(e *Executor, args []string) Reply {
+	return popReply(e.LPop(args[0]))
+}
+
+func rPop(e *Executor, args []string) Reply {
+	return popReply(e.RPop(args[0]))
+}
+
+func popReply(value interface{}, ok bool, err error) Reply {
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return formatListValue(value)
+}
+
+func lRange(e *Executor, args []string) Reply {
+	start, stop, err := parseRange(args[1], args[2])
+	if err != nil {
+		return err
+	}
+	values, err := e.LRange(args[0], start, stop)
+	if err != nil {
+		return err
+	}
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = formatListValue(value)
+	}
+	return result
+}
+
+func lTrim(e *Executor, args []string) Reply {
+	start, stop, err := parseRange(args[1], args[2])
+	if err != nil {
+		return err
+	}
+	if err := e.LTrim(args[0], start, stop); err != nil {
+		return err
+	}
+	return StatusOK
+}
+
+func lIndex(e *Executor, args []string) Reply {
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		return ErrNotInteger
+	}
+	return popReply(e.LIndex(args[0], index))
+}
+
+func lSet(e *Executor, args []string) Reply {
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		return ErrNotInteger
+	}
+	if err := e.LSet(args[0], index, args[2]); err != nil {
+		return err
+	}
+	return StatusOK
+}
+
+func lLen(e *Executor, args []string) Reply {
+	return countReply(e.LLen(args[0]))
+}
+
+// formatListValue converts list value into string, values of composite types are formatted by fmt.
+func formatListValue(value interface{}) string {
+	if result, ok := formatValue(value); ok {
+		return result
+	}
+	return fmt.Sprint(value)
+}
+
+func parseRange(start, stop string) (int, int, error) {
+	startIndex, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, ErrNotInteger
+	}
+	stopIndex, err := strconv.Atoi(stop)
+	if err != nil {
+		return 0, 0, ErrNotInteger
+	}
+	return startIndex, stopIndex, nil
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute_pushAndPop(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, 2, executor.Execute([]string{"RPUSH", "phones", "Xiaomi", "Samsung"}))
+	assert.Equal(t, 3, executor.Execute([]string{"LPUSH", "phones", "Nokia"}))
+	assert.Equal(t, "Nokia", executor.Execute([]string{"LPOP", "phones"}))
+	assert.Equal(t, "Samsung", executor.Execute([]string{"RPOP", "phones"}))
+	assert.Equal(t, "Xiaomi", executor.Execute([]string{"RPOP", "phones"}))
+	assert.Equal(t, nil, executor.Execute([]string{"RPOP", "phones"}))
+}
+
+func TestExecutor_Execute_lRangeAndLTrim(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.Execute([]string{"RPUSH", "numbers", "0", "1", "2", "3"})
+
+	assert.Equal(t, []string{"1", "2", "3"}, executor.Execute([]string{"LRANGE", "numbers", "1", "-1"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"LTRIM", "numbers", "0", "1"}))
+	assert.Equal(t, []string{"0", "1"}, executor.Execute([]string{"LRANGE", "numbers", "0", "-1"}))
+	assert.Equal(t, []string{}, executor.Execute([]string{"LRANGE", "absent", "0", "-1"}))
+	assert.Equal(t, ErrNotInteger, executor.Execute([]string{"LRANGE", "numbers", "first", "-1"}))
+}
+
+func TestExecutor_Execute_lIndexLSetLLen(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("marks", datatype.NewList([]interface{}{9.0, "seven"}, time.Minute))
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, "9", executor.Execute([]string{"LINDEX", "marks", "0"}))
+	assert.Equal(t, nil, executor.Execute([]string{"LINDEX", "marks", "5"}))
+	assert.Equal(t, StatusOK, executor.Execute([]string{"LSET", "marks", "-1", "8"}))
+	assert.Equal(t, "8", executor.Execute([]string{"LINDEX", "marks", "1"}))
+	assert.Equal(t, 2, executor.Execute([]string{"LLEN", "marks"}))
+	assert.Equal(t, ErrNotFound, executor.Execute([]string{"LSET", "absent", "0", "8"}))
+	assert.Equal(t, ErrOutOfRange, executor.Execute([]string{"LSET", "marks", "5", "8"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"LLEN", "name"}))
+}
+
+func TestExecutor_LSet(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("marks", datatype.NewList([]interface{}{9.0, "seven"}, time.Minute))
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Nil(t, executor.LSet("marks", 0, 8.0))
+	value, ok, err := executor.LIndex("marks", 0)
+	assert.Equal(t, 8.0, value)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	assert.Equal(t, ErrNotFound, executor.LSet("absent", 0, 8.0))
+	assert.Equal(t, ErrOutOfRange, executor.LSet("marks", 5, 8.0))
+	assert.Equal(t, ErrWrongType, executor.LSet("name", 0, 8.0))
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnbalancedQuotes = errors.New("ERR unbalanced quotes in request")
+
+// Parse splits text command line into arguments.
+// Arguments are separated by whitespace; single or double quotes could be used
+// for arguments with spaces, backslash escapes next character inside double quotes.
+func Parse(line string) ([]string, error) {
+	args := []string{}
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errUnbalancedQuotes
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
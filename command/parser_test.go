@@ -0,0 +1,39 @@
+package command
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	args, err := Parse("SET  name Ivan 60s\r\n")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SET", "name", "Ivan", "60s"}, args)
+}
+
+func TestParse_quotes(t *testing.T) {
+	args, err := Parse(`SET "full name" 'Ivan Ivanov' "say \"hi\"" ""`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SET", "full name", "Ivan Ivanov", `say "hi"`, ""}, args)
+}
+
+func TestParse_empty(t *testing.T) {
+	args, err := Parse("  \r\n")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, args)
+}
+
+func TestParse_unbalancedQuotes(t *testing.T) {
+	_, err := Parse(`GET "name`)
+	assert.Equal(t, errUnbalancedQuotes, err)
+}
+
+func ExampleParse() {
+	Parse(`SET name "Ivan Ivanov" 60s`)
+}
+
+func BenchmarkParse(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		Parse(`SET name "Ivan Ivanov" 60s`)
+	}
+}
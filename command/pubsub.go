@@ -0,0 +1,38 @@
+package command
+
+import (
+	"errors"
+	"strings"
+)
+
+var errPubSubDisabled = errors.New("ERR publish/subscribe is disabled")
+
+// publish sends message to the channel and returns amount of receivers.
+func publish(e *Executor, args []string) Reply {
+	if e.broker == nil {
+		return errPubSubDisabled
+	}
+	return e.broker.Publish(args[0], args[1])
+}
+
+// pubSub supports subcommands "PUBSUB CHANNELS [pattern]" and "PUBSUB NUMSUB [channel]".
+func pubSub(e *Executor, args []string) Reply {
+	if e.broker == nil {
+		return errPubSubDisabled
+	}
+	switch strings.ToUpper(args[0]) {
+	case "CHANNELS":
+		pattern := "*"
+		if len(args) == 2 {
+			pattern = args[1]
+		}
+		return e.broker.Channels(pattern)
+	case "NUMSUB":
+		if len(args) == 1 {
+			return []interface{}{}
+		}
+		return []interface{}{args[1], e.broker.Subscribers(args[1])}
+	default:
+		return errSyntax
+	}
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/pubsub"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute_publish(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	assert.Equal(t, errPubSubDisabled, executor.Execute([]string{"PUBLISH", "news", "hello"}))
+
+	broker := pubsub.NewBroker(pubsub.DefaultBufferSize)
+	executor.SetBroker(broker)
+	subscription := broker.Subscribe()
+	subscription.Subscribe("news")
+
+	assert.Equal(t, 1, executor.Execute([]string{"PUBLISH", "news", "hello"}))
+	assert.Equal(t, 0, executor.Execute([]string{"PUBLISH", "weather", "rain"}))
+	assert.Equal(t, pubsub.Message{Channel: "news", Payload: "hello"}, <-subscription.Messages())
+
+	replies, err := executor.ExecuteAll(nil, [][]string{{"SET", "name", "Ivan"}, {"PUBLISH", "news", "name"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Reply{StatusOK, 1}, replies, "Messages are published from transaction")
+}
+
+func TestExecutor_Execute_pubSub(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	broker := pubsub.NewBroker(pubsub.DefaultBufferSize)
+	executor.SetBroker(broker)
+	broker.Subscribe().Subscribe("news", "news.sport", "weather")
+
+	assert.Equal(t, []string{"news", "news.sport", "weather"}, executor.Execute([]string{"PUBSUB", "CHANNELS"}))
+	assert.Equal(t, []string{"news", "news.sport"}, executor.Execute([]string{"pubsub", "channels", "news*"}))
+	assert.Equal(t, []interface{}{"news", 1}, executor.Execute([]string{"PUBSUB", "NUMSUB", "news"}))
+	assert.Equal(t, []interface{}{}, executor.Execute([]string{"PUBSUB", "NUMSUB"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"PUBSUB", "SHARDS"}))
+}
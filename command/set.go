@@ -0,0 +1,125 @@
+package command
+
+// SAdd adds members to the set and returns amount of added members.
+func (e *Executor) SAdd(key string, members ...string) (int, error) {
+	added, err := e.storage.SAdd(key, members...)
+	return added, storageError(err)
+}
+
+// SRem removes members from the set and returns amount of removed members.
+func (e *Executor) SRem(key string, members ...string) (int, error) {
+	removed, err := e.storage.SRem(key, members...)
+	return removed, storageError(err)
+}
+
+// SIsMember returns flag is the member present in the set.
+func (e *Executor) SIsMember(key, member string) (bool, error) {
+	isMember, err := e.storage.SIsMember(key, member)
+	return isMember, storageError(err)
+}
+
+// SMembers returns sorted members of the set.
+func (e *Executor) SMembers(key string) ([]string, error) {
+	members, err := e.storage.SMembers(key)
+	return members, storageError(err)
+}
+
+// SCard returns amount of members of the set.
+func (e *Executor) SCard(key string) (int, error) {
+	count, err := e.storage.SCard(key)
+	return count, storageError(err)
+}
+
+// SUnion returns sorted members of union of the sets.
+func (e *Executor) SUnion(keys ...string) ([]string, error) {
+	members, err := e.storage.SUnion(keys...)
+	return members, storageError(err)
+}
+
+// SInter returns sorted members of intersection of the sets.
+func (e *Executor) SInter(keys ...string) ([]string, error) {
+	members, err := e.storage.SInter(keys...)
+	return members, storageError(err)
+}
+
+// SDiff returns sorted members of the first set which are absent in the other ones.
+func (e *Executor) SDiff(keys ...string) ([]string, error) {
+	members, err := e.storage.SDiff(keys...)
+	return members, storageError(err)
+}
+
+// SUnionStore saves union of the sets under destination key and returns amount of its members.
+func (e *Executor) SUnionStore(destination string, keys ...string) (int, error) {
+	count, err := e.storage.SUnionStore(destination, keys...)
+	return count, storageError(err)
+}
+
+// SInterStore saves intersection of the sets under destination key and returns amount of its members.
+func (e *Executor) SInterStore(destination string, keys ...string) (int, error) {
+	count, err := e.storage.SInterStore(destination, keys...)
+	return count, storageError(err)
+}
+
+// SDiffStore saves difference of the sets under destination key and returns amount of its members.
+func (e *Executor) SDiffStore(destination string, keys ...string) (int, error) {
+	count, err := e.storage.SDiffStore(destination, keys...)
+	return count, storageError(err)
+}
+
+func sAdd(e *Executor, args []string) Reply {
+	return countReply(e.SAdd(args[0], args[1:]...))
+}
+
+func sRem(e *Executor, args []string) Reply {
+	return countReply(e.SRem(args[0], args[1:]...))
+}
+
+func sIsMember(e *Executor, args []string) Reply {
+	return flagReply(e.SIsMember(args[0], args[1]))
+}
+
+func sMembers(e *Executor, args []string) Reply {
+	return membersReply(e.SMembers(args[0]))
+}
+
+func sCard(e *Executor, args []string) Reply {
+	return countReply(e.SCard(args[0]))
+}
+
+func sUnion(e *Executor, args []string) Reply {
+	return membersReply(e.SUnion(args...))
+}
+
+func sInter(e *Executor, args []string) Reply {
+	return membersReply(e.SInter(args...))
+}
+
+func sDiff(e *Executor, args []string) Reply {
+	return membersReply(e.SDiff(args...))
+}
+
+func sUnionStore(e *Executor, args []string) Reply {
+	return countReply(e.SUnionStore(args[0], args[1:]...))
+}
+
+func sInterStore(e *Executor, args []string) Reply {
+	return countReply(e.SInterStore(args[0], args[1:]...))
+}
+
+func sDiffStore(e *Executor, args []string) Reply {
+	return countReply(e.SDiffStore(args[0], args[1:]...))
+}
+
+func membersReply(members []string, err error) Reply {
+	if err != nil {
+		return err
+	}
+	return members
+}
+
+func countReply(count int, err error) Reply {
+	if err != nil {
+		return err
+	}
+	return count
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute_sAddSRemSMembers(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, 2, executor.Execute([]string{"SADD", "flags", "dark-mode", "beta", "beta"}))
+	assert.Equal(t, 1, executor.Execute([]string{"SISMEMBER", "flags", "beta"}))
+	assert.Equal(t, 0, executor.Execute([]string{"SISMEMBER", "flags", "alpha"}))
+	assert.Equal(t, 2, executor.Execute([]string{"SCARD", "flags"}))
+	assert.Equal(t, []string{"beta", "dark-mode"}, executor.Execute([]string{"SMEMBERS", "flags"}))
+	assert.Equal(t, 1, executor.Execute([]string{"SREM", "flags", "beta", "alpha"}))
+	assert.Equal(t, []string{"dark-mode"}, executor.Execute([]string{"SMEMBERS", "flags"}))
+	assert.Equal(t, []string{}, executor.Execute([]string{"SMEMBERS", "absent"}))
+}
+
+func TestExecutor_Execute_setAlgebra(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.Execute([]string{"SADD", "ivan", "dark-mode", "beta"})
+	executor.Execute([]string{"SADD", "petr", "beta", "export"})
+
+	assert.Equal(t, []string{"beta", "dark-mode", "export"}, executor.Execute([]string{"SUNION", "ivan", "petr"}))
+	assert.Equal(t, []string{"beta"}, executor.Execute([]string{"SINTER", "ivan", "petr"}))
+	assert.Equal(t, []string{"dark-mode"}, executor.Execute([]string{"SDIFF", "ivan", "petr"}))
+	assert.Equal(t, 3, executor.Execute([]string{"SUNIONSTORE", "all", "ivan", "petr"}))
+	assert.Equal(t, 1, executor.Execute([]string{"SINTERSTORE", "common", "ivan", "petr"}))
+	assert.Equal(t, 1, executor.Execute([]string{"SDIFFSTORE", "only", "ivan", "petr"}))
+	assert.Equal(t, []string{"beta", "dark-mode", "export"}, executor.Execute([]string{"SMEMBERS", "all"}))
+}
+
+func TestExecutor_Execute_setsWrongType(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("flags", datatype.NewSet([]string{"beta"}, time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"SADD", "name", "beta"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"SUNION", "flags", "name"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"GET", "flags"}))
+}
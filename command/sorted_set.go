@@ -0,0 +1,221 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errScoreBound = errors.New("ERR min or max is not a float")
+
+// ZAdd sets scores of the sorted set members and returns amount of added members.
+func (e *Executor) ZAdd(key string, members map[string]float64) (int, error) {
+	added, err := e.storage.ZAdd(key, members)
+	return added, storageError(err)
+}
+
+// ZIncrBy increments score of the sorted set member by delta and returns new score.
+func (e *Executor) ZIncrBy(key, member string, delta float64) (float64, error) {
+	score, err := e.storage.ZIncrBy(key, member, delta)
+	return score, storageError(err)
+}
+
+// ZRem removes members from the sorted set and returns amount of removed members.
+func (e *Executor) ZRem(key string, members ...string) (int, error) {
+	removed, err := e.storage.ZRem(key, members...)
+	return removed, storageError(err)
+}
+
+// ZScore returns score of the sorted set member. Returns false if there is no such member.
+func (e *Executor) ZScore(key, member string) (float64, bool, error) {
+	score, ok, err := e.storage.ZScore(key, member)
+	return score, ok, storageError(err)
+}
+
+// ZCard returns amount of members of the sorted set.
+func (e *Executor) ZCard(key string) (int, error) {
+	count, err := e.storage.ZCard(key)
+	return count, storageError(err)
+}
+
+// ZRank returns rank of the sorted set member, reversed rank counts from the highest score.
+// Returns false if there is no such member.
+func (e *Executor) ZRank(key, member string, reversed bool) (int, bool, error) {
+	rank, ok, err := e.storage.ZRank(key, member, reversed)
+	return rank, ok, storageError(err)
+}
+
+// ZRange returns members of the sorted set between start and stop ranks inclusive.
+func (e *Executor) ZRange(key string, start, stop int, reversed bool) ([]datatype.ScoredMember, error) {
+	members, err := e.storage.ZRange(key, start, stop, reversed)
+	return members, storageError(err)
+}
+
+// ZRangeByScore returns members of the sorted set with scores in the range.
+// Negative count means that all members after offset are returned.
+func (e *Executor) ZRangeByScore(key string, scoreRange datatype.ScoreRange, reversed bool, offset, count int) ([]datatype.ScoredMember, error) {
+	members, err := e.storage.ZRangeByScore(key, scoreRange, reversed, offset, count)
+	return members, storageError(err)
+}
+
+// zAdd supports multiple score-member pairs: "ZADD key score member [score member ...]".
+func zAdd(e *Executor, args []string) Reply {
+	pairs := args[1:]
+	if len(pairs)%2 != 0 {
+		return fmt.Errorf("ERR wrong number of arguments for 'zadd' command")
+	}
+	members := make(map[string]float64, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		score, ok := parseScore(pairs[i])
+		if !ok {
+			return ErrNotFloat
+		}
+		members[pairs[i+1]] = score
+	}
+	return countReply(e.ZAdd(args[0], members))
+}
+
+func zIncrBy(e *Executor, args []string) Reply {
+	delta, ok := parseScore(args[1])
+	if !ok {
+		return ErrNotFloat
+	}
+	score, err := e.ZIncrBy(args[0], args[2], delta)
+	if err != nil {
+		return err
+	}
+	return formatScore(score)
+}
+
+func zRem(e *Executor, args []string) Reply {
+	return countReply(e.ZRem(args[0], args[1:]...))
+}
+
+func zScore(e *Executor, args []string) Reply {
+	score, ok, err := e.ZScore(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return formatScore(score)
+}
+
+func zCard(e *Executor, args []string) Reply {
+	return countReply(e.ZCard(args[0]))
+}
+
+func zRank(e *Executor, args []string) Reply {
+	return rankReply(e.ZRank(args[0], args[1], false))
+}
+
+func zRevRank(e *Executor, args []string) Reply {
+	return rankReply(e.ZRank(args[0], args[1], true))
+}
+
+// zRange supports options: "ZRANGE key start stop [WITHSCORES] [REV]".
+func zRange(e *Executor, args []string) Reply {
+	start, stop, err := parseRange(args[1], args[2])
+	if err != nil {
+		return err
+	}
+	withScores, reversed := false, false
+	for _, option := range args[3:] {
+		switch strings.ToUpper(option) {
+		case "WITHSCORES":
+			withScores = true
+		case "REV":
+			reversed = true
+		default:
+			return errSyntax
+		}
+	}
+	members, err := e.ZRange(args[0], start, stop, reversed)
+	if err != nil {
+		return err
+	}
+	return scoredMembersReply(members, withScores)
+}
+
+// zRangeByScore supports options: "ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count]".
+// Bounds could be "-inf", "+inf" or exclusive ones prefixed by "(".
+func zRangeByScore(e *Executor, args []string) Reply {
+	var scoreRange datatype.ScoreRange
+	var ok bool
+	if scoreRange.Min, scoreRange.MinExclusive, ok = parseScoreBound(args[1]); !ok {
+		return errScoreBound
+	}
+	if scoreRange.Max, scoreRange.MaxExclusive, ok = parseScoreBound(args[2]); !ok {
+		return errScoreBound
+	}
+	withScores, offset, count := false, 0, -1
+	options := args[3:]
+	for i := 0; i < len(options); i++ {
+		switch strings.ToUpper(options[i]) {
+		case "WITHSCORES":
+			withScores = true
+		case "LIMIT":
+			if i+2 >= len(options) {
+				return errSyntax
+			}
+			var err error
+			if offset, err = strconv.Atoi(options[i+1]); err != nil || offset < 0 {
+				return ErrNotInteger
+			}
+			if count, err = strconv.Atoi(options[i+2]); err != nil {
+				return ErrNotInteger
+			}
+			i += 2
+		default:
+			return errSyntax
+		}
+	}
+	members, err := e.ZRangeByScore(args[0], scoreRange, false, offset, count)
+	if err != nil {
+		return err
+	}
+	return scoredMembersReply(members, withScores)
+}
+
+func rankReply(rank int, ok bool, err error) Reply {
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return rank
+}
+
+// scoredMembersReply returns members, each one followed by its score when withScores is true.
+func scoredMembersReply(members []datatype.ScoredMember, withScores bool) Reply {
+	result := make([]string, 0, len(members))
+	for _, member := range members {
+		result = append(result, member.Member)
+		if withScores {
+			result = append(result, formatScore(member.Score))
+		}
+	}
+	return result
+}
+
+// parseScore parses score, "-inf" and "+inf" are supported for bounds of ranges.
+func parseScore(value string) (float64, bool) {
+	score, err := strconv.ParseFloat(value, 64)
+	return score, err == nil && !math.IsNaN(score)
+}
+
+// parseScoreBound parses bound of score range, exclusive bound is prefixed by "(".
+func parseScoreBound(value string) (float64, bool, bool) {
+	exclusive := strings.HasPrefix(value, "(")
+	score, ok := parseScore(strings.TrimPrefix(value, "("))
+	return score, exclusive, ok
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Execute_zAddZIncrByZScore(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	assert.Equal(t, 2, executor.Execute([]string{"ZADD", "leaders", "150", "ivan", "90", "petr"}))
+	assert.Equal(t, 0, executor.Execute([]string{"ZADD", "leaders", "200", "ivan"}))
+	assert.Equal(t, "210.5", executor.Execute([]string{"ZINCRBY", "leaders", "10.5", "ivan"}))
+	assert.Equal(t, "10", executor.Execute([]string{"ZINCRBY", "leaders", "10", "anna"}))
+	assert.Equal(t, "90", executor.Execute([]string{"ZSCORE", "leaders", "petr"}))
+	assert.Equal(t, nil, executor.Execute([]string{"ZSCORE", "leaders", "olga"}))
+	assert.Equal(t, 3, executor.Execute([]string{"ZCARD", "leaders"}))
+	assert.Equal(t, 1, executor.Execute([]string{"ZREM", "leaders", "anna", "olga"}))
+	assert.Equal(t, ErrNotFloat, executor.Execute([]string{"ZINCRBY", "leaders", "+inf", "petr"}))
+
+	assert.Equal(t, ErrNotFloat, executor.Execute([]string{"ZADD", "leaders", "many", "ivan"}))
+	assert.Equal(t, ErrNotFloat, executor.Execute([]string{"ZADD", "leaders", "nan", "ivan"}))
+	assert.Equal(t, "ERR wrong number of arguments for 'zadd' command",
+		executor.Execute([]string{"ZADD", "leaders", "1", "ivan", "2"}).(error).Error())
+}
+
+func TestExecutor_Execute_zRankZRange(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.Execute([]string{"ZADD", "leaders", "150", "ivan", "90", "petr", "300", "olga"})
+
+	assert.Equal(t, 1, executor.Execute([]string{"ZRANK", "leaders", "ivan"}))
+	assert.Equal(t, 0, executor.Execute([]string{"ZREVRANK", "leaders", "olga"}))
+	assert.Equal(t, nil, executor.Execute([]string{"ZRANK", "leaders", "anna"}))
+	assert.Equal(t, []string{"petr", "ivan", "olga"}, executor.Execute([]string{"ZRANGE", "leaders", "0", "-1"}))
+	assert.Equal(t, []string{"olga", "300", "ivan", "150"},
+		executor.Execute([]string{"ZRANGE", "leaders", "0", "1", "withscores", "REV"}))
+	assert.Equal(t, []string{}, executor.Execute([]string{"ZRANGE", "absent", "0", "-1"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"ZRANGE", "leaders", "0", "-1", "BYLEX"}))
+}
+
+func TestExecutor_Execute_zRangeByScore(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.Execute([]string{"ZADD", "tasks", "100", "backup", "200", "report", "300", "cleanup"})
+
+	assert.Equal(t, []string{"backup", "report"}, executor.Execute([]string{"ZRANGEBYSCORE", "tasks", "-inf", "200"}))
+	assert.Equal(t, []string{"report", "200"},
+		executor.Execute([]string{"ZRANGEBYSCORE", "tasks", "(100", "(300", "WITHSCORES"}))
+	assert.Equal(t, []string{"report"},
+		executor.Execute([]string{"ZRANGEBYSCORE", "tasks", "-inf", "+inf", "LIMIT", "1", "1"}))
+	assert.Equal(t, errScoreBound, executor.Execute([]string{"ZRANGEBYSCORE", "tasks", "low", "200"}))
+	assert.Equal(t, errSyntax, executor.Execute([]string{"ZRANGEBYSCORE", "tasks", "0", "200", "LIMIT", "1"}))
+}
+
+func TestExecutor_Execute_sortedSetsWrongType(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	executor := NewExecutor(storage, time.Minute)
+
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"ZADD", "name", "1", "ivan"}))
+	assert.Equal(t, ErrWrongType, executor.Execute([]string{"ZRANGE", "name", "0", "-1"}))
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"errors"
+	"github.com/andrei-punko/go-cache/datastore"
+)
+
+// ErrAborted is returned when transaction is not executed because watched item was changed.
+var ErrAborted = errors.New("EXECABORT Transaction discarded because watched key was changed")
+
+// ExecuteAll runs commands atomically: other clients see either none or all of their changes.
+// Watch contains versions of items read before the transaction (zero version means absent item),
+// ErrAborted is returned without running commands if any of them was changed since then.
+// Unknown commands and wrong amount of arguments are detected before execution, error of the first such
+// command is returned in this case. Errors of executed commands are returned as their replies,
+// the rest of commands are executed anyway. SAVE and BGREWRITEAOF are not supported inside transaction.
+func (e *Executor) ExecuteAll(watch map[string]uint64, commands [][]string) ([]Reply, error) {
+	for _, args := range commands {
+		if _, err := lookup(args); err != nil {
+			return nil, err
+		}
+	}
+
+	replies := make([]Reply, 0, len(commands))
+	err := e.storage.Transaction(watch, func(tx *datastore.DataStore) error {
+		executor := NewExecutor(tx, e.defaultTtl)
+		executor.SetBroker(e.broker)
+		for _, args := range commands {
+			replies = append(replies, executor.Execute(args))
+		}
+		return nil
+	})
+	if err == datastore.ErrWatchedKeyChanged {
+		return nil, ErrAborted
+	}
+	return replies, err
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"errors"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExecutor_ExecuteAll(t *testing.T) {
+	storage := datastore.NewDataStore()
+	executor := NewExecutor(storage, time.Minute)
+	executor.Execute([]string{"RPUSH", "todo", "buy milk", "call Ivan"})
+	executor.Execute([]string{"SET", "name", "Ivan"})
+
+	replies, err := executor.ExecuteAll(nil, [][]string{
+		{"LPOP", "todo"},
+		{"RPUSH", "done", "buy milk"},
+		{"INCR", "done:count"},
+		{"INCR", "name"},
+		{"GET", "done:count"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Reply{"buy milk", 1, int64(1), ErrNotInteger, "1"}, replies, "Failed command doesn't stop transaction")
+	assert.Equal(t, []string{"call Ivan"}, executor.Execute([]string{"LRANGE", "todo", "0", "-1"}))
+}
+
+func TestExecutor_ExecuteAll_invalidCommand(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+
+	replies, err := executor.ExecuteAll(nil, [][]string{{"SET", "name", "Ivan"}, {"INCR"}})
+	assert.Nil(t, replies)
+	assert.Equal(t, errors.New("ERR wrong number of arguments for 'incr' command"), err)
+	_, err = executor.ExecuteAll(nil, [][]string{{"SET", "name", "Ivan"}, {"MOVE", "name"}})
+	assert.Equal(t, errors.New("ERR unknown command 'MOVE'"), err)
+	assert.Equal(t, nil, executor.Execute([]string{"GET", "name"}), "Nothing is executed")
+}
+
+func TestExecutor_ExecuteAll_watch(t *testing.T) {
+	storage := datastore.NewDataStore()
+	executor := NewExecutor(storage, time.Minute)
+	executor.Execute([]string{"SET", "balance", "100"})
+	watch := map[string]uint64{"balance": storage.Version("balance")}
+
+	executor.Execute([]string{"INCRBY", "balance", "-30"})
+	replies, err := executor.ExecuteAll(watch, [][]string{{"INCRBY", "balance", "-50"}})
+	assert.Nil(t, replies)
+	assert.Equal(t, ErrAborted, err)
+	assert.Equal(t, "70", executor.Execute([]string{"GET", "balance"}))
+
+	watch["balance"] = storage.Version("balance")
+	replies, err = executor.ExecuteAll(watch, [][]string{{"INCRBY", "balance", "-50"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Reply{int64(20)}, replies)
+}
+
+func TestExecutor_ExecuteAll_save(t *testing.T) {
+	executor := NewExecutor(datastore.NewDataStore(), time.Minute)
+	saver := &stubSaver{}
+	executor.SetSaver(saver)
+
+	replies, err := executor.ExecuteAll(nil, [][]string{{"SAVE"}})
+	assert.Nil(t, err)
+	assert.IsType(t, errors.New(""), replies[0], "SAVE is not supported inside transaction")
+	assert.Equal(t, 0, saver.calls)
+}
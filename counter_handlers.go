@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IncrementItem atomically increments numeric value of the item by "by" query parameter (1 by default)
+// and returns new value. Integer increment is used when "by" is an integer, float increment otherwise.
+// Absent item is created with TTL from "ttl" query parameter in Go duration format (never expires by default).
+func IncrementItem(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	var ttl time.Duration
+	if value := query.Get("ttl"); value != "" {
+		var err error
+		if ttl, err = time.ParseDuration(value); err != nil || ttl < 0 {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+	}
+
+	key := mux.Vars(request)["key"]
+	by := query.Get("by")
+	if by == "" {
+		by = "1"
+	}
+	var value interface{}
+	var err error
+	if delta, parseErr := strconv.ParseInt(by, 10, 64); parseErr == nil {
+		value, err = Commands.IncrBy(key, delta, ttl)
+	} else if delta, parseErr := strconv.ParseFloat(by, 64); parseErr == nil {
+		value, err = Commands.IncrByFloat(key, delta, ttl)
+	} else {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, value)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCounterServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}/incr", IncrementItem).Methods(http.MethodPost)
+	return httptest.NewServer(router)
+}
+
+func TestIncrementItem(t *testing.T) {
+	Storage.Clear()
+	server := newCounterServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/visits/incr?ttl=1m", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `1`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/visits/incr?by=-3", "")
+	assert.Equal(t, `-2`, readBody(t, response))
+	ttl, _ := Storage.Ttl("visits")
+	assert.Equal(t, true, ttl > 59*time.Second && ttl <= time.Minute, "TTL is set on first increment")
+
+	response = doRequest(t, http.MethodPost, server.URL+"/items/weight/incr?by=0.5", "")
+	assert.Equal(t, `0.5`, readBody(t, response))
+	ttl, _ = Storage.Ttl("weight")
+	assert.Equal(t, datastore.NoTtl, ttl)
+}
+
+func TestIncrementItem_errors(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server := newCounterServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/visits/incr?by=many", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = doRequest(t, http.MethodPost, server.URL+"/items/visits/incr?ttl=-1s", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = doRequest(t, http.MethodPost, server.URL+"/items/name/incr", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
@@ -0,0 +1,50 @@
+package datastore
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"time"
+)
+
+// Conditional writes check state of the item and store new one atomically, so they could be used for locks
+// and optimistic concurrency. Expired item is treated as absent.
+
+// SetNX stores the item only if there is no item with such key. Returns stored item with assigned version
+// or false if the item already exists.
+func (ds *DataStore) SetNX(key string, value datatype.DataType) (datatype.DataType, bool) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	if s.contains(key) {
+		return datatype.DataType{}, false
+	}
+	return s.set(key, value), true
+}
+
+// SetXX stores the item only if there is an item with such key. Returns stored item with assigned version
+// or false if there is no such item.
+func (ds *DataStore) SetXX(key string, value datatype.DataType) (datatype.DataType, bool) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	if !s.contains(key) {
+		return datatype.DataType{}, false
+	}
+	return s.set(key, value), true
+}
+
+// CompareAndSwap stores the item only if version of existing item equals provided one.
+// Returns stored item with assigned version, ErrNotFound if there is no such item
+// or ErrVersionMismatch if the item was changed since provided version was read.
+func (ds *DataStore) CompareAndSwap(key string, version uint64, value datatype.DataType) (datatype.DataType, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	current, ok := s.lookup(key)
+	if !ok || current.IsExpired(time.Now()) {
+		return datatype.DataType{}, ErrNotFound
+	}
+	if current.Version != version {
+		return datatype.DataType{}, ErrVersionMismatch
+	}
+	return s.set(key, value), nil
+}
@@ -0,0 +1,110 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataStore_Set_assignsVersions(t *testing.T) {
+	dataStore := NewDataStore()
+
+	first := dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	second := dataStore.Set("name", datatype.NewString("Petr", time.Minute))
+	assert.Equal(t, true, first.Version > 0)
+	assert.Equal(t, true, second.Version > first.Version)
+	value, _ := dataStore.Get("name")
+	assert.Equal(t, second.Version, value.(datatype.DataType).Version, "Version is returned on read")
+
+	dataStore.RPush("name:list", "Nokia")
+	value, _ = dataStore.Get("name:list")
+	assert.Equal(t, true, value.(datatype.DataType).Version > second.Version, "Each write increments version")
+	item, _ := dataStore.Expire("name", time.Hour)
+	assert.Equal(t, true, item.Version > value.(datatype.DataType).Version)
+
+	restarted := NewDataStore()
+	assert.Equal(t, true, restarted.Set("name", datatype.NewString("Ivan", 0)).Version > first.Version,
+		"Versions keep growing after restart")
+}
+
+func TestDataStore_SetNX(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("expired", datatype.NewString("Ivan", -time.Second))
+
+	item, ok := dataStore.SetNX("lock", datatype.NewString("owner-1", time.Minute))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "owner-1", item.Value)
+	_, ok = dataStore.SetNX("lock", datatype.NewString("owner-2", time.Minute))
+	assert.Equal(t, false, ok)
+	value, _ := dataStore.Get("lock")
+	assert.Equal(t, "owner-1", value.(datatype.DataType).Value)
+
+	_, ok = dataStore.SetNX("expired", datatype.NewString("Petr", time.Minute))
+	assert.Equal(t, true, ok, "Expired item is treated as absent")
+}
+
+func TestDataStore_SetXX(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	item, ok := dataStore.SetXX("name", datatype.NewString("Petr", time.Minute))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Petr", item.Value)
+	_, ok = dataStore.SetXX("city", datatype.NewString("Minsk", time.Minute))
+	assert.Equal(t, false, ok)
+	assert.Equal(t, false, dataStore.Contains("city"))
+}
+
+func TestDataStore_CompareAndSwap(t *testing.T) {
+	dataStore := NewDataStore()
+	original := dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	item, err := dataStore.CompareAndSwap("name", original.Version, datatype.NewString("Petr", time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, "Petr", item.Value)
+	_, err = dataStore.CompareAndSwap("name", original.Version, datatype.NewString("Anna", time.Minute))
+	assert.Equal(t, ErrVersionMismatch, err, "Stale version is rejected")
+	_, err = dataStore.CompareAndSwap("city", original.Version, datatype.NewString("Minsk", time.Minute))
+	assert.Equal(t, ErrNotFound, err)
+
+	value, _ := dataStore.Get("name")
+	assert.Equal(t, "Petr", value.(datatype.DataType).Value)
+}
+
+func TestDataStore_CompareAndSwap_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("counter", datatype.DataType{Value: 0})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; {
+				value, _ := dataStore.Get("counter")
+				item := value.(datatype.DataType)
+				next := datatype.DataType{Value: item.Value.(int) + 1}
+				if _, err := dataStore.CompareAndSwap("counter", item.Version, next); err == nil {
+					j++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _ := dataStore.Get("counter")
+	assert.Equal(t, 1000, value.(datatype.DataType).Value, "No updates are lost")
+}
+
+func ExampleDataStore_SetNX() {
+	dataStore := NewDataStore()
+	_, acquired := dataStore.SetNX("lock", datatype.NewString("owner-1", time.Minute))
+	fmt.Println(acquired)
+	_, acquired = dataStore.SetNX("lock", datatype.NewString("owner-2", time.Minute))
+	fmt.Println(acquired)
+	// Output:
+	// true
+	// false
+}
@@ -0,0 +1,127 @@
+package datastore
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Counter operations work with items holding numbers or strings with numbers.
+// Absent counter is created with provided TTL and treated as zero, TTL of existing item is kept.
+// String value stays string after increment, so values saved by SET command of RESP protocol remain compatible.
+
+// IncrBy increments integer value stored under the key by delta and returns new value.
+// Zero TTL means that created item never expires.
+// Returns ErrNotInteger if the item holds value which is not an integer or the result overflows.
+func (ds *DataStore) IncrBy(key string, delta int64, ttl time.Duration) (int64, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, err := s.counter(key, ttl)
+	if err != nil {
+		return 0, err
+	}
+	current, ok := toInteger(item.Value)
+	if !ok {
+		return 0, ErrNotInteger
+	}
+	sum, ok := addInteger(current, delta)
+	if !ok {
+		return 0, ErrNotInteger
+	}
+	if _, isString := item.Value.(string); isString {
+		item.Value = strconv.FormatInt(sum, 10)
+	} else {
+		item.Value = sum
+	}
+	s.set(key, item)
+	return sum, nil
+}
+
+// IncrByFloat increments numeric value stored under the key by delta and returns new value.
+// Zero TTL means that created item never expires.
+// Returns ErrNotFloat if the item holds value which is not a number or the result is not finite.
+func (ds *DataStore) IncrByFloat(key string, delta float64, ttl time.Duration) (float64, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, err := s.counter(key, ttl)
+	if err != nil {
+		return 0, err
+	}
+	current, ok := toFloat(item.Value)
+	if !ok {
+		return 0, ErrNotFloat
+	}
+	sum := current + delta
+	if !isFinite(sum) {
+		return 0, ErrNotFloat
+	}
+	if _, isString := item.Value.(string); isString {
+		item.Value = strconv.FormatFloat(sum, 'f', -1, 64)
+	} else {
+		item.Value = sum
+	}
+	s.set(key, item)
+	return sum, nil
+}
+
+// counter returns item stored under the key. New item with zero value and provided TTL is returned for absent key.
+func (s *shard) counter(key string, ttl time.Duration) (datatype.DataType, error) {
+	item, ok := s.lookup(key)
+	if !ok || item.IsExpired(time.Now()) {
+		return datatype.DataType{Value: int64(0), Ttl: ttl, DeathTime: datatype.DeathTimeOf(ttl)}, nil
+	}
+	switch item.Value.(type) {
+	case []interface{}, map[string]interface{}, map[interface{}]interface{}, datatype.Set, *datatype.SortedSet:
+		return item, ErrWrongType
+	default:
+		return item, nil
+	}
+}
+
+// addInteger returns sum of the integers and false if it overflows.
+func addInteger(a, b int64) (int64, bool) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// toInteger converts value into integer. Integral floats (json numbers) and strings with integers are supported.
+func toInteger(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case string:
+		result, err := strconv.ParseInt(v, 10, 64)
+		return result, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toFloat converts value into finite float. Integers, floats and strings with numbers are supported.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		result, err := strconv.ParseFloat(v, 64)
+		return result, err == nil && isFinite(result)
+	default:
+		return 0, false
+	}
+}
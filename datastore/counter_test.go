@@ -0,0 +1,121 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataStore_IncrBy(t *testing.T) {
+	dataStore := NewDataStore()
+
+	value, err := dataStore.IncrBy("visits", 5, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), value)
+	value, _ = dataStore.IncrBy("visits", -7, time.Hour)
+	assert.Equal(t, int64(-2), value)
+
+	item, _ := dataStore.Get("visits")
+	assert.Equal(t, int64(-2), item.(datatype.DataType).Value)
+	ttl, _ := dataStore.Ttl("visits")
+	assert.Equal(t, true, ttl > 59*time.Second && ttl <= time.Minute, "TTL is set on first increment only")
+}
+
+func TestDataStore_IncrBy_keepsTypeOfValue(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("visits", datatype.NewString("10", 0))
+	dataStore.Set("weight", datatype.DataType{Value: 70.0})
+
+	dataStore.IncrBy("visits", 1, time.Minute)
+	dataStore.IncrBy("weight", 1, time.Minute)
+
+	item, _ := dataStore.Get("visits")
+	assert.Equal(t, "11", item.(datatype.DataType).Value)
+	item, _ = dataStore.Get("weight")
+	assert.Equal(t, int64(71), item.(datatype.DataType).Value)
+	ttl, _ := dataStore.Ttl("visits")
+	assert.Equal(t, NoTtl, ttl)
+}
+
+func TestDataStore_IncrBy_errors(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.DataType{Value: 70.5})
+	dataStore.Set("phones", datatype.NewList([]interface{}{"Nokia"}, time.Minute))
+	dataStore.Set("max", datatype.DataType{Value: int64(math.MaxInt64)})
+
+	_, err := dataStore.IncrBy("name", 1, 0)
+	assert.Equal(t, ErrNotInteger, err)
+	_, err = dataStore.IncrBy("weight", 1, 0)
+	assert.Equal(t, ErrNotInteger, err)
+	_, err = dataStore.IncrBy("phones", 1, 0)
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.IncrBy("max", 1, 0)
+	assert.Equal(t, ErrNotInteger, err)
+	item, _ := dataStore.Get("max")
+	assert.Equal(t, int64(math.MaxInt64), item.(datatype.DataType).Value, "Value is not changed on overflow")
+}
+
+func TestDataStore_IncrBy_expiredItem(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("visits", datatype.DataType{Value: int64(10), Ttl: time.Minute, DeathTime: time.Now().Add(-time.Second)})
+
+	value, _ := dataStore.IncrBy("visits", 1, time.Hour)
+	assert.Equal(t, int64(1), value, "Expired counter starts from zero")
+	ttl, _ := dataStore.Ttl("visits")
+	assert.Equal(t, true, ttl > 59*time.Minute)
+}
+
+func TestDataStore_IncrBy_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dataStore.IncrBy("visits", 1, time.Minute)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _ := dataStore.IncrBy("visits", 0, time.Minute)
+	assert.Equal(t, int64(1000), value)
+}
+
+func TestDataStore_IncrByFloat(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("price", datatype.NewString("10.5", 0))
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("phones", datatype.NewList([]interface{}{"Nokia"}, time.Minute))
+
+	value, err := dataStore.IncrByFloat("weight", 0.5, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, value)
+	value, _ = dataStore.IncrByFloat("weight", 70, time.Minute)
+	assert.Equal(t, 70.5, value)
+	value, _ = dataStore.IncrByFloat("price", 0.25, time.Minute)
+	assert.Equal(t, 10.75, value)
+	item, _ := dataStore.Get("price")
+	assert.Equal(t, "10.75", item.(datatype.DataType).Value)
+
+	_, err = dataStore.IncrByFloat("name", 1, 0)
+	assert.Equal(t, ErrNotFloat, err)
+	_, err = dataStore.IncrByFloat("phones", 1, 0)
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.IncrByFloat("weight", math.Inf(1), 0)
+	assert.Equal(t, ErrNotFloat, err)
+}
+
+func ExampleDataStore_IncrBy() {
+	dataStore := NewDataStore()
+	dataStore.IncrBy("requests", 1, time.Minute)
+	value, _ := dataStore.IncrBy("requests", 1, time.Minute)
+	fmt.Println(value)
+	// Output: 2
+}
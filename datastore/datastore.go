@@ -3,19 +3,180 @@ package datastore
 import (
 	"github.com/umpc/go-sortedmap"
 	"github.com/andrei-punko/go-cache/datatype"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// DataStore contains map and mutex to protect it.
+// DefaultShards is the amount of shards of DataStore without size limits.
+const DefaultShards = 32
+
+// DataStore contains items partitioned by hash of their keys into shards, each shard has its own lock
+// and DeathTime ordering, so operations with keys of different shards don't block each other.
 type DataStore struct {
-	sync.RWMutex
+	shards []*shard
+	*hub
+
+	// options applied by NewDataStore, see Option
+	shardCount int
+	maxItems   int
+	maxMemory  int64
+	itemSize   func(datatype.DataType) int64
+	policy     EvictionPolicy
+}
+
+// rwLocker protects state of shard. It is sync.RWMutex for DataStore and no-op one for transactions,
+// which are executed under the lock of DataStore they are started from.
+type rwLocker interface {
+	sync.Locker
+	RLock()
+	RUnlock()
+}
+
+// shard is a partition of the collection with its own lock.
+type shard struct {
+	rwLocker
+	*state
+}
+
+// state contains the partition shared by shard and its copies used by transactions.
+type state struct {
+	*hub
 	cache sortedmap.SortedMap
+
+	// persistent contains items without expiration, they are kept outside of DeathTime ordering
+	persistent map[string]datatype.DataType
+
+	// lists contains backing buffers of lists changed by list operations
+	lists map[string]*listBuffer
+
+	// maxItems and maxMemory limit size of the collection, zero value means no limit
+	maxItems  int
+	maxMemory int64
+	memory    int64
+	evicted   int64
+	policy    EvictionPolicy
+
+	// itemSize estimates memory occupied by item, DataType.EstimateSize is used when it is nil
+	itemSize func(datatype.DataType) int64
+}
+
+// hub contains state shared by all shards of DataStore.
+type hub struct {
+	// version is the last version assigned to stored item, it is started from current time in microseconds,
+	// so versions keep growing after restart of the application. It is the first field to be 64-bit aligned.
+	version uint64
+
+	// listeners are changed under the lock of all shards, so they could be read under the lock of any shard
+	listeners []Listener
+
+	watchMutex sync.Mutex
+	watchers   map[*Watcher]bool
+	// watcherCount is the amount of watchers, it is read without watchMutex, so writers don't contend
+	// for it when nobody watches
+	watcherCount int32
+
+	// expirerStop and expirerDone control background expirer, see StartExpirer
+	expirerMutex sync.Mutex
+	expirerStop  chan struct{}
+	expirerDone  sync.WaitGroup
+
+	// loads contains running loads and failed ones during loadErrorTtl, see GetOrLoad
+	loadMutex    sync.Mutex
+	loads        map[string]*load
+	loadErrorTtl time.Duration
+}
+
+// Option configures DataStore.
+type Option func(*DataStore)
+
+// WithMaxItems limits amount of items in the collection.
+// Items chosen by eviction policy are evicted when the limit is exceeded.
+func WithMaxItems(maxItems int) Option {
+	return func(ds *DataStore) {
+		ds.maxItems = maxItems
+	}
+}
+
+// WithMaxMemory limits estimated amount of memory in bytes occupied by keys and values of the collection.
+// Items chosen by eviction policy are evicted when the limit is exceeded.
+func WithMaxMemory(maxMemory int64) Option {
+	return func(ds *DataStore) {
+		ds.maxMemory = maxMemory
+	}
+}
+
+// WithItemSize sets function estimating amount of memory in bytes occupied by item, it replaces DataType.EstimateSize
+// for memory limit, for example for values of custom types which can't be estimated by it.
+func WithItemSize(itemSize func(item datatype.DataType) int64) Option {
+	return func(ds *DataStore) {
+		ds.itemSize = itemSize
+	}
+}
+
+// WithShards sets amount of shards, not positive value means DefaultShards.
+// Eviction policy needs to see all items, so the collection with size limits always has a single shard.
+func WithShards(shards int) Option {
+	return func(ds *DataStore) {
+		ds.shardCount = shards
+	}
+}
+
+// Stats contains statistics of the collection.
+type Stats struct {
+	Count   int   `json:"count"`
+	Memory  int64 `json:"memory"`
+	Evicted int64 `json:"evicted"`
+}
+
+// Entry is a key-value pair stored in the collection.
+type Entry struct {
+	Key   string
+	Value datatype.DataType
 }
 
 // NewDataStore creates and initializes a new DataStore structure and then returns a reference to it.
 // DataStore is concurrency-safe.
-func NewDataStore() *DataStore {
-	return &DataStore{cache: buildSortedMap()}
+func NewDataStore(options ...Option) *DataStore {
+	ds := &DataStore{hub: &hub{
+		watchers:     make(map[*Watcher]bool),
+		loads:        make(map[string]*load),
+		loadErrorTtl: DefaultLoadErrorTtl,
+		version:      uint64(time.Now().UnixNano() / int64(time.Microsecond)),
+	}}
+	for _, option := range options {
+		option(ds)
+	}
+	if ds.shardCount <= 0 {
+		ds.shardCount = DefaultShards
+	}
+	if !ds.isBounded() {
+		ds.policy = nil
+	} else {
+		ds.shardCount = 1
+		if ds.policy == nil {
+			ds.policy = NewLruPolicy()
+		}
+	}
+
+	ds.shards = make([]*shard, ds.shardCount)
+	for i := range ds.shards {
+		ds.shards[i] = &shard{rwLocker: &sync.RWMutex{}, state: &state{
+			hub:        ds.hub,
+			cache:      buildSortedMap(),
+			persistent: make(map[string]datatype.DataType),
+			lists:      make(map[string]*listBuffer),
+			maxItems:   ds.maxItems,
+			maxMemory:  ds.maxMemory,
+			policy:     ds.policy,
+			itemSize:   ds.itemSize,
+		}}
+	}
+	if policy, ok := ds.policy.(boundPolicy); ok {
+		policy.bind(ds.shards[0])
+	}
+	return ds
 }
 
 // compareDataTypesByDeathTime compares DataType items by DeathTime.
@@ -30,55 +191,433 @@ func buildSortedMap() sortedmap.SortedMap {
 	return *sortedmap.New(10, compareDataTypesByDeathTime)
 }
 
-func (ds *DataStore) set(key string, value datatype.DataType) {
-	ds.cache.Replace(key, value)
+// entrySize returns estimated amount of memory occupied by key-value pair.
+func entrySize(key string, value interface{}) int64 {
+	return int64(len(key)) + value.(datatype.DataType).EstimateSize()
 }
 
-func (ds *DataStore) get(key string) (interface{}, bool) {
-	return ds.cache.Get(key)
+// entrySize returns estimated amount of memory occupied by key-value pair using item size function of the collection.
+func (s *state) entrySize(key string, value interface{}) int64 {
+	if s.itemSize == nil {
+		return entrySize(key, value)
+	}
+	return int64(len(key)) + s.itemSize(value.(datatype.DataType))
 }
 
-func (ds *DataStore) getKeys() []interface{} {
-	keys := ds.cache.Keys()
-	if keys == nil {
-		return []interface{}{}
+func (ds *DataStore) isBounded() bool {
+	return ds.maxItems > 0 || ds.maxMemory > 0
+}
+
+// shardFor returns shard of the key.
+func (ds *DataStore) shardFor(key string) *shard {
+	return ds.shards[ds.shardIndex(key)]
+}
+
+// shardIndex returns index of the shard chosen by FNV-1a hash of the key.
+func (ds *DataStore) shardIndex(key string) int {
+	if len(ds.shards) == 1 {
+		return 0
+	}
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return int(hash % uint32(len(ds.shards)))
+}
+
+// lockShards locks shards of the keys in the order of their indexes, so concurrent callers never deadlock.
+// Returned function unlocks them.
+func (ds *DataStore) lockShards(keys []string, write bool) func() {
+	used := make([]bool, len(ds.shards))
+	for _, key := range keys {
+		used[ds.shardIndex(key)] = true
+	}
+	var locked []*shard
+	for i, s := range ds.shards {
+		if !used[i] {
+			continue
+		}
+		if write {
+			s.Lock()
+		} else {
+			s.RLock()
+		}
+		locked = append(locked, s)
+	}
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			if write {
+				locked[i].Unlock()
+			} else {
+				locked[i].RUnlock()
+			}
+		}
+	}
+}
+
+// Lock locks all shards for writing.
+func (ds *DataStore) Lock() {
+	for _, s := range ds.shards {
+		s.Lock()
+	}
+}
+
+// Unlock unlocks all shards locked by Lock.
+func (ds *DataStore) Unlock() {
+	for i := len(ds.shards) - 1; i >= 0; i-- {
+		ds.shards[i].Unlock()
+	}
+}
+
+// RLock locks all shards for reading.
+func (ds *DataStore) RLock() {
+	for _, s := range ds.shards {
+		s.RLock()
+	}
+}
+
+// RUnlock unlocks all shards locked by RLock.
+func (ds *DataStore) RUnlock() {
+	for i := len(ds.shards) - 1; i >= 0; i-- {
+		ds.shards[i].RUnlock()
+	}
+}
+
+// nextVersion returns version for the next stored item. Versions are unique across all shards.
+func (h *hub) nextVersion() uint64 {
+	return atomic.AddUint64(&h.version, 1)
+}
+
+func (s *shard) isOverLimit() bool {
+	return (s.maxItems > 0 && s.cache.Len()+len(s.persistent) > s.maxItems) || (s.maxMemory > 0 && s.memory > s.maxMemory)
+}
+
+// lookup returns item with provided key regardless of its expiration.
+func (s *shard) lookup(key string) (datatype.DataType, bool) {
+	if value, ok := s.persistent[key]; ok {
+		return value, true
+	}
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return datatype.DataType{}, false
+	}
+	return value.(datatype.DataType), true
+}
+
+// set stores the item with next version and returns stored item.
+func (s *shard) set(key string, value datatype.DataType) datatype.DataType {
+	sizeDelta := s.entrySize(key, value)
+	if oldValue, ok := s.lookup(key); ok {
+		sizeDelta -= s.entrySize(key, oldValue)
+	}
+	return s.store(key, value, sizeDelta)
+}
+
+// store stores the item with next version changing used memory by sizeDelta and returns stored item.
+// It is used directly by operations which track size of changed item without estimating it again.
+func (s *shard) store(key string, value datatype.DataType, sizeDelta int64) datatype.DataType {
+	value.Version = s.nextVersion()
+	value.Modified = time.Now()
+	delete(s.lists, key)
+	if value.IsPersistent() {
+		s.cache.Delete(key)
+		s.persistent[key] = value
+	} else {
+		delete(s.persistent, key)
+		s.cache.Replace(key, value)
+	}
+	s.memory += sizeDelta
+	s.notify(Operation{Type: OperationSet, Key: key, Value: value})
+
+	if s.policy != nil {
+		s.policy.Add(key)
+		s.evictIfNeeded()
+	}
+	return value
+}
+
+// evictIfNeeded evicts items chosen by eviction policy until the collection fits its limits.
+func (s *shard) evictIfNeeded() {
+	for s.isOverLimit() {
+		key, ok := s.policy.Victim()
+		if !ok {
+			return
+		}
+		s.policy.Remove(key)
+		if s.remove(key) {
+			s.evicted++
+			s.notify(Operation{Type: OperationEvict, Key: key})
+		}
+	}
+}
+
+// remove deletes key from the collection without notification of listeners.
+func (s *shard) remove(key interface{}) bool {
+	value, ok := s.lookup(key.(string))
+	if !ok {
+		return false
+	}
+	if value.IsPersistent() {
+		delete(s.persistent, key.(string))
+	} else {
+		s.cache.Delete(key)
 	}
+	delete(s.lists, key.(string))
+	s.memory -= s.entrySize(key.(string), value)
+	if s.policy != nil {
+		s.policy.Remove(key.(string))
+	}
+	return true
+}
+
+// isExpired returns flag is DeathTime of the item passed.
+func isExpired(value interface{}, now time.Time) bool {
+	return value.(datatype.DataType).IsExpired(now)
+}
+
+// expiredKeys returns keys of up to limit expired items, all of them when limit is not positive.
+// Items are ordered by DeathTime, so expired ones are the first.
+func (s *shard) expiredKeys(now time.Time, limit int) []interface{} {
+	var keys []interface{}
+	s.cache.IterFunc(false, func(record sortedmap.Record) bool {
+		if !isExpired(record.Val, now) {
+			return false
+		}
+		keys = append(keys, record.Key)
+		return limit <= 0 || len(keys) < limit
+	})
 	return keys
 }
 
-func (ds *DataStore) delete(key interface{}) bool {
-	return ds.cache.Delete(key)
+// hasExpired returns flag is there at least one expired item in the shard.
+func (s *shard) hasExpired(now time.Time) bool {
+	expired := false
+	s.cache.IterFunc(false, func(record sortedmap.Record) bool {
+		expired = isExpired(record.Val, now)
+		return false
+	})
+	return expired
 }
 
-func (ds *DataStore) batchDelete(keys []interface{}) []bool {
-	return ds.cache.BatchDelete(keys)
+// expire removes expired items with provided keys and notifies listeners about each of them.
+func (s *shard) expire(keys []interface{}, now time.Time) {
+	for _, key := range keys {
+		if value, ok := s.cache.Get(key); ok && isExpired(value, now) {
+			s.remove(key)
+			s.notify(Operation{Type: OperationExpire, Key: key.(string)})
+		}
+	}
+}
+
+func (s *shard) get(key string) (interface{}, bool) {
+	value, ok := s.lookup(key)
+	if !ok || value.IsExpired(time.Now()) {
+		return nil, false
+	}
+	if s.policy != nil {
+		s.policy.Touch(key)
+	}
+	return value, true
+}
+
+// volatileEntries returns not expired items with TTL ordered by DeathTime.
+func (s *shard) volatileEntries(now time.Time) []Entry {
+	entries := make([]Entry, 0, s.cache.Len())
+	s.cache.IterFunc(false, func(record sortedmap.Record) bool {
+		if !isExpired(record.Val, now) {
+			entries = append(entries, Entry{record.Key.(string), record.Val.(datatype.DataType)})
+		}
+		return true
+	})
+	return entries
 }
 
-func (ds *DataStore) contains(key string) bool {
-	_, ok := ds.cache.Get(key)
+func (s *shard) delete(key interface{}) bool {
+	ok := s.remove(key)
+	if ok {
+		s.notify(Operation{Type: OperationDelete, Key: key.(string)})
+	}
 	return ok
 }
 
+func (s *shard) contains(key string) bool {
+	value, ok := s.lookup(key)
+	return ok && !value.IsExpired(time.Now())
+}
+
+func (s *shard) count() int {
+	return s.cache.Len() + len(s.persistent) - len(s.expiredKeys(time.Now(), 0))
+}
+
+func (s *shard) clear() {
+	s.cache = buildSortedMap()
+	s.persistent = make(map[string]datatype.DataType)
+	s.lists = make(map[string]*listBuffer)
+	s.memory = 0
+	if s.policy != nil {
+		s.policy.Reset()
+	}
+}
+
+// getKeys returns keys of items with TTL ordered by DeathTime followed by sorted keys of items without TTL.
+// Should be called under the lock of all shards.
+func (ds *DataStore) getKeys() []interface{} {
+	entries := ds.entries()
+	result := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Key
+	}
+	return result
+}
+
+// batchDelete removes keys and notifies listeners once per shard. Should be called under the lock of shards of the keys.
+func (ds *DataStore) batchDelete(keys []interface{}) []bool {
+	results := make([]bool, len(keys))
+	deletedKeys := make([][]string, len(ds.shards))
+	for i, key := range keys {
+		index := ds.shardIndex(key.(string))
+		results[i] = ds.shards[index].remove(key)
+		if results[i] {
+			deletedKeys[index] = append(deletedKeys[index], key.(string))
+		}
+	}
+	for i, s := range ds.shards {
+		if len(deletedKeys[i]) > 0 {
+			s.notify(Operation{Type: OperationBatchDelete, Keys: deletedKeys[i]})
+		}
+	}
+	return results
+}
+
+// count should be called under the lock of all shards.
 func (ds *DataStore) count() int {
-	return ds.cache.Len()
+	count := 0
+	for _, s := range ds.shards {
+		count += s.count()
+	}
+	return count
 }
 
-// Set adds provided key-value pair to the collection.
-func (ds *DataStore) Set(key string, value datatype.DataType) {
-	ds.Lock()
-	defer ds.Unlock()
-	ds.set(key, value)
+// entries returns not expired items with TTL ordered by DeathTime followed by items without TTL ordered by key.
+// Should be called under the lock of all shards.
+func (ds *DataStore) entries() []Entry {
+	now := time.Now()
+	var result, persistent []Entry
+	for _, s := range ds.shards {
+		result = append(result, s.volatileEntries(now)...)
+		for key, value := range s.persistent {
+			persistent = append(persistent, Entry{key, value})
+		}
+	}
+	if len(ds.shards) > 1 {
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Value.DeathTime.Before(result[j].Value.DeathTime)
+		})
+	}
+	sort.Slice(persistent, func(i, j int) bool {
+		return persistent[i].Key < persistent[j].Key
+	})
+	if result == nil {
+		result = make([]Entry, 0, len(persistent))
+	}
+	return append(result, persistent...)
+}
+
+// clear should be called under the lock of all shards.
+func (ds *DataStore) clear() {
+	for _, s := range ds.shards {
+		s.clear()
+	}
+	ds.shards[0].notify(Operation{Type: OperationClear})
 }
 
-// Get returns value for provided key stored in the collection.
+// stats should be called under the lock of all shards.
+func (ds *DataStore) stats() Stats {
+	stats := Stats{}
+	for _, s := range ds.shards {
+		stats.Count += s.count()
+		stats.Memory += s.memory
+		stats.Evicted += s.evicted
+	}
+	return stats
+}
+
+// Set adds provided key-value pair to the collection and returns stored item with assigned version.
+func (ds *DataStore) Set(key string, value datatype.DataType) datatype.DataType {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	return s.set(key, value)
+}
+
+// Get returns value for provided key stored in the collection. Expired item is removed and treated as absent.
+// DeathTime of sliding item is pushed forward by its Ttl.
 func (ds *DataStore) Get(key string) (interface{}, bool) {
-	ds.RLock()
-	defer ds.RUnlock()
-	return ds.get(key)
+	s := ds.shardFor(key)
+	s.RLock()
+	value, ok := s.get(key)
+	s.RUnlock()
+	if !ok {
+		s.expireKey(key)
+		return value, ok
+	}
+	if value.(datatype.DataType).Sliding {
+		s.Lock()
+		defer s.Unlock()
+		return s.slide(key)
+	}
+	return value, ok
+}
+
+// slide pushes DeathTime of sliding item forward and updates its position in DeathTime ordering.
+// Listeners are notified by OperationSlide, which is not reported to watchers.
+func (s *shard) slide(key string) (interface{}, bool) {
+	value, ok := s.lookup(key)
+	now := time.Now()
+	if !ok {
+		return nil, false
+	}
+	if value.IsExpired(now) {
+		s.expire([]interface{}{key}, now)
+		return nil, false
+	}
+	slid := value.Slide(now)
+	if !slid.DeathTime.Equal(value.DeathTime) {
+		s.cache.Replace(key, slid)
+		s.notify(Operation{Type: OperationSlide, Key: key, Value: slid})
+	}
+	return slid, true
 }
 
-// GetKeys returns all keys stored in the collection.
+// expireKey removes item with provided key if it is expired. Write lock is taken only when it is needed.
+func (s *shard) expireKey(key string) {
+	s.RLock()
+	value, ok := s.cache.Get(key)
+	s.RUnlock()
+	if ok && isExpired(value, time.Now()) {
+		s.Lock()
+		defer s.Unlock()
+		s.expire([]interface{}{key}, time.Now())
+	}
+}
+
+// expireAll removes all expired items in batches. Write lock of a shard is taken only when it has expired items.
+func (ds *DataStore) expireAll() {
+	for _, s := range ds.shards {
+		s.RLock()
+		expired := s.hasExpired(time.Now())
+		s.RUnlock()
+		if expired {
+			for s.removeExpired(DefaultExpireBatchSize) == DefaultExpireBatchSize {
+			}
+		}
+	}
+}
+
+// GetKeys returns all keys stored in the collection. Expired items are removed and not returned.
 func (ds *DataStore) GetKeys() []interface{} {
+	ds.expireAll()
 	ds.RLock()
 	defer ds.RUnlock()
 	return ds.getKeys()
@@ -86,32 +625,49 @@ func (ds *DataStore) GetKeys() []interface{} {
 
 // Delete deletes provided key from the collection.
 func (ds *DataStore) Delete(key interface{}) bool {
-	ds.Lock()
-	defer ds.Unlock()
-	return ds.delete(key)
+	s := ds.shardFor(key.(string))
+	s.Lock()
+	defer s.Unlock()
+	return s.delete(key)
 }
 
 // BatchDelete deletes provided keys from the collection.
 func (ds *DataStore) BatchDelete(keys []interface{}) []bool {
-	ds.Lock()
-	defer ds.Unlock()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.(string)
+	}
+	defer ds.lockShards(names, true)()
 	return ds.batchDelete(keys)
 }
 
-// Contains returns flag is this key present in the collection.
+// Contains returns flag is this key present in the collection. Expired item is removed and treated as absent.
 func (ds *DataStore) Contains(key string) bool {
-	ds.RLock()
-	defer ds.RUnlock()
-	return ds.contains(key)
+	s := ds.shardFor(key)
+	s.RLock()
+	ok := s.contains(key)
+	s.RUnlock()
+	if !ok {
+		s.expireKey(key)
+	}
+	return ok
 }
 
-// Count returns amount of items in the collection.
+// Count returns amount of items in the collection. Expired items are removed and not counted.
 func (ds *DataStore) Count() int {
+	ds.expireAll()
 	ds.RLock()
 	defer ds.RUnlock()
 	return ds.count()
 }
 
+// Entries returns all not expired key-value pairs stored in the collection ordered by DeathTime.
+func (ds *DataStore) Entries() []Entry {
+	ds.RLock()
+	defer ds.RUnlock()
+	return ds.entries()
+}
+
 // Clear removes all items from the collection.
 func (ds *DataStore) Clear() {
 	ds.Lock()
@@ -119,6 +675,9 @@ func (ds *DataStore) Clear() {
 	ds.clear()
 }
 
-func (ds *DataStore) clear() {
-	ds.cache = buildSortedMap()
+// Stats returns statistics of the collection: amount of items, estimated memory usage and amount of evicted items.
+func (ds *DataStore) Stats() Stats {
+	ds.RLock()
+	defer ds.RUnlock()
+	return ds.stats()
 }
@@ -5,40 +5,43 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/andrei-punko/go-cache/datatype"
 	"github.com/andrei-punko/go-cache/util"
+	"math/rand"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestNewDataStore(t *testing.T) {
-	dataStore := NewDataStore()
-	assert.Equal(t, dataStore.cache.Len(), 0, "map should be empty")
+	dataStore := NewDataStore(WithShards(1))
+	assert.Equal(t, dataStore.shards[0].cache.Len(), 0, "map should be empty")
 }
 
 func TestDataStore_set(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
 
-	dataStore.set(key, value)
-	actualValue, _ := dataStore.cache.Get(key)
-	assert.Equal(t, value, actualValue)
+	stored := dataStore.shards[0].set(key, value)
+	actualValue, _ := dataStore.shards[0].cache.Get(key)
+	assert.Equal(t, stored, actualValue)
+	assert.Equal(t, value.Value, stored.Value)
 }
 
 func TestDataStore_get(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
-	dataStore.cache.Insert(key, value)
+	dataStore.shards[0].cache.Insert(key, value)
 
-	actualValue, ok := dataStore.get(key)
+	actualValue, ok := dataStore.shards[0].get(key)
 	assert.Equal(t, value, actualValue)
 	assert.Equal(t, true, ok)
 }
 
 func TestDataStore_getKeys(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
 
 	keys := dataStore.getKeys()
 	assert.Equal(t, len(keys), 2)
@@ -47,87 +50,98 @@ func TestDataStore_getKeys(t *testing.T) {
 }
 
 func TestDataStore_delete(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
-	dataStore.cache.Insert(key, value)
+	dataStore.shards[0].cache.Insert(key, value)
 
-	assert.Equal(t, dataStore.delete(key), true, "existing key")
-	assert.Equal(t, dataStore.cache.Len(), 0, "map should be empty")
-	assert.Equal(t, dataStore.delete(key), false, "absent key")
-	assert.Equal(t, dataStore.delete("another key"), false, "absent key 2")
+	assert.Equal(t, dataStore.shards[0].delete(key), true, "existing key")
+	assert.Equal(t, dataStore.shards[0].cache.Len(), 0, "map should be empty")
+	assert.Equal(t, dataStore.shards[0].delete(key), false, "absent key")
+	assert.Equal(t, dataStore.shards[0].delete("another key"), false, "absent key 2")
 }
 
 func TestDataStore_batchDelete(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
-	dataStore.cache.Insert("key 3", datatype.NewString("value 3", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore.shards[0].cache.Insert("key 3", datatype.NewString("value 3", time.Minute))
 	keys := util.StringListToInterfaceList([]string{"key 1", "key N", "key 2", "key Z"})
 
 	results := dataStore.batchDelete(keys)
 	assert.Equal(t, 4, len(results), "Two items in result array expected")
 	assert.Equal(t, []bool{true, false, true, false}, results)
-	assert.Equal(t, 1, dataStore.cache.Len(), "One item should remain")
-	assert.Equal(t, true, dataStore.cache.Has("key 3"))
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "One item should remain")
+	assert.Equal(t, true, dataStore.shards[0].cache.Has("key 3"))
 }
 
 func TestDataStore_BatchDelete(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
-	dataStore.cache.Insert("key 3", datatype.NewString("value 3", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore.shards[0].cache.Insert("key 3", datatype.NewString("value 3", time.Minute))
 	keys := util.StringListToInterfaceList([]string{"key 1", "key N", "key 2", "key Z"})
 
 	results := dataStore.BatchDelete(keys)
 	assert.Equal(t, 4, len(results), "Two items in result array expected")
 	assert.Equal(t, []bool{true, false, true, false}, results)
-	assert.Equal(t, 1, dataStore.cache.Len(), "One item should remain")
-	assert.Equal(t, true, dataStore.cache.Has("key 3"))
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "One item should remain")
+	assert.Equal(t, true, dataStore.shards[0].cache.Has("key 3"))
 }
 
 func TestDataStore_contains(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
-	dataStore.cache.Insert(key, value)
+	dataStore.shards[0].cache.Insert(key, value)
 
-	assert.Equal(t, dataStore.contains(key), true, "existing key")
-	assert.Equal(t, dataStore.contains("another key"), false, "absent key")
+	assert.Equal(t, dataStore.shards[0].contains(key), true, "existing key")
+	assert.Equal(t, dataStore.shards[0].contains("another key"), false, "absent key")
 }
 
 func TestDataStore_count(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
 
 	assert.Equal(t, dataStore.count(), 2)
 }
 
+func TestDataStore_entries(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	value1 := datatype.NewString("value 1", 2*time.Minute)
+	value2 := datatype.NewString("value 2", time.Minute)
+	dataStore.shards[0].cache.Insert("key 1", value1)
+	dataStore.shards[0].cache.Insert("key 2", value2)
+
+	assert.Equal(t, []Entry{{"key 2", value2}, {"key 1", value1}}, dataStore.entries())
+}
+
 func TestDataStore_clear(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
 
 	dataStore.clear()
-	assert.Equal(t, 0, dataStore.cache.Len(), "Storage should be empty")
+	assert.Equal(t, 0, dataStore.shards[0].cache.Len(), "Storage should be empty")
 }
 
 func TestDataStore_Set(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
 
-	dataStore.Set(key, value)
-	actualValue, _ := dataStore.cache.Get(key)
-	assert.Equal(t, value, actualValue)
+	stored := dataStore.Set(key, value)
+	actualValue, _ := dataStore.shards[0].cache.Get(key)
+	assert.Equal(t, stored, actualValue)
+	assert.Equal(t, value.Value, stored.Value)
 }
 
 func TestDataStore_Get(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
-	dataStore.cache.Insert(key, value)
+	dataStore.shards[0].cache.Insert(key, value)
 
 	res, ok := dataStore.Get(key)
 	assert.Equal(t, value, res)
@@ -141,12 +155,12 @@ func TestDataStore_Get(t *testing.T) {
 }
 
 func TestDataStore_GetKeys(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	keys := dataStore.GetKeys()
 	assert.Equal(t, []interface{}{}, keys, "Should return empty array in case of empty storage")
 
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
 
 	keys = dataStore.GetKeys()
 	assert.Equal(t, len(keys), 2)
@@ -155,42 +169,193 @@ func TestDataStore_GetKeys(t *testing.T) {
 }
 
 func TestDataStore_Delete(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
-	dataStore.cache.Insert(key, value)
+	dataStore.shards[0].cache.Insert(key, value)
 
 	assert.Equal(t, dataStore.Delete(key), true, "existing key")
-	assert.Equal(t, dataStore.cache.Len(), 0, "map should be empty")
+	assert.Equal(t, dataStore.shards[0].cache.Len(), 0, "map should be empty")
 	assert.Equal(t, dataStore.Delete(key), false, "absent key")
 	assert.Equal(t, dataStore.Delete("another key"), false, "absent key 2")
 }
 
 func TestDataStore_Contains(t *testing.T) {
-	dataStore := NewDataStore()
+	dataStore := NewDataStore(WithShards(1))
 	key := "Some key"
 	value := datatype.NewString("Some value", time.Minute)
-	dataStore.cache.Insert(key, value)
+	dataStore.shards[0].cache.Insert(key, value)
 
 	assert.Equal(t, dataStore.Contains(key), true, "existing key")
 	assert.Equal(t, dataStore.Contains("another key"), false, "absent key")
 }
 
 func TestDataStore_Count(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
 
 	assert.Equal(t, dataStore.Count(), 2)
 }
 
+func TestDataStore_Entries(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	assert.Equal(t, []Entry{}, dataStore.Entries(), "Should return empty array in case of empty storage")
+
+	value := datatype.NewString("value 1", time.Minute)
+	dataStore.shards[0].cache.Insert("key 1", value)
+	assert.Equal(t, []Entry{{"key 1", value}}, dataStore.Entries())
+}
+
 func TestDataStore_Clear(t *testing.T) {
-	dataStore := NewDataStore()
-	dataStore.cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
-	dataStore.cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.shards[0].cache.Insert("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.shards[0].cache.Insert("key 2", datatype.NewString("value 2", time.Minute))
 
 	dataStore.Clear()
-	assert.Equal(t, 0, dataStore.cache.Len(), "Storage should be empty")
+	assert.Equal(t, 0, dataStore.shards[0].cache.Len(), "Storage should be empty")
+}
+
+func TestDataStore_Stats(t *testing.T) {
+	dataStore := NewDataStore()
+	value := datatype.NewString("value 1", time.Minute)
+	dataStore.Set("key 1", value)
+	dataStore.Set("key 2", value)
+	dataStore.Set("key 2", value)
+
+	stats := dataStore.Stats()
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, 2*entrySize("key 1", value), stats.Memory)
+	assert.Equal(t, int64(0), stats.Evicted)
+
+	dataStore.Delete("key 1")
+	assert.Equal(t, entrySize("key 2", value), dataStore.Stats().Memory)
+	dataStore.Clear()
+	assert.Equal(t, int64(0), dataStore.Stats().Memory)
+}
+
+func TestWithMaxItems(t *testing.T) {
+	dataStore := NewDataStore(WithMaxItems(2))
+	var evictions []string
+	dataStore.AddListener(func(operation Operation) {
+		if operation.Type == OperationEvict {
+			evictions = append(evictions, operation.Key)
+		}
+	})
+	dataStore.Set("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.Set("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore.Get("key 1")
+
+	dataStore.Set("key 3", datatype.NewString("value 3", time.Minute))
+	assert.Equal(t, 2, dataStore.Count())
+	assert.Equal(t, false, dataStore.Contains("key 2"), "Least recently used item should be evicted")
+	assert.Equal(t, true, dataStore.Contains("key 1"))
+	assert.Equal(t, true, dataStore.Contains("key 3"))
+	assert.Equal(t, int64(1), dataStore.Stats().Evicted)
+	assert.Equal(t, []string{"key 2"}, evictions)
+}
+
+func TestWithMaxMemory(t *testing.T) {
+	value := datatype.NewString("value", time.Minute)
+	dataStore := NewDataStore(WithMaxMemory(3 * entrySize("key 1", value)))
+	for i := 1; i <= 5; i++ {
+		dataStore.Set(fmt.Sprintf("key %d", i), value)
+	}
+
+	assert.Equal(t, 3, dataStore.Count())
+	assert.Equal(t, true, util.ContainsAll(dataStore.GetKeys(), []interface{}{"key 3", "key 4", "key 5"}))
+	assert.Equal(t, int64(2), dataStore.Stats().Evicted)
+	assert.Equal(t, true, dataStore.Stats().Memory <= 3*entrySize("key 1", value))
+
+	dataStore.Set("huge", datatype.NewString(util.RandString(1000), time.Minute))
+	assert.Equal(t, false, dataStore.Contains("huge"), "Item bigger than limit could not be stored")
+}
+
+func TestWithItemSize(t *testing.T) {
+	itemSize := func(item datatype.DataType) int64 {
+		return 100
+	}
+	dataStore := NewDataStore(WithMaxMemory(250), WithItemSize(itemSize))
+	dataStore.Set("a", datatype.NewString("value", time.Minute))
+	dataStore.Set("b", datatype.NewString("value", time.Minute))
+	assert.Equal(t, int64(202), dataStore.Stats().Memory)
+
+	dataStore.Set("c", datatype.NewString("value", time.Minute))
+	assert.Equal(t, 2, dataStore.Count())
+	assert.Equal(t, false, dataStore.Contains("a"), "Item should be evicted by size of item size function")
+	dataStore.Delete("b")
+	assert.Equal(t, int64(101), dataStore.Stats().Memory)
+}
+
+func TestDataStore_Get_expired(t *testing.T) {
+	dataStore := NewDataStore()
+	var expired []string
+	dataStore.AddListener(func(operation Operation) {
+		if operation.Type == OperationExpire {
+			expired = append(expired, operation.Key)
+		}
+	})
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+
+	_, ok := dataStore.Get("weight")
+	assert.Equal(t, false, ok, "Expired item should be treated as absent")
+	assert.Equal(t, []string{"weight"}, expired, "Expired item should be removed")
+	_, ok = dataStore.Get("name")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []string{"weight"}, expired)
+}
+
+func TestDataStore_Contains_expired(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+
+	assert.Equal(t, false, dataStore.Contains("weight"))
+	assert.Equal(t, 0, dataStore.shards[0].cache.Len(), "Expired item should be removed")
+}
+
+func TestDataStore_Count_expired(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+	dataStore.Set("age", datatype.NewString("27", -time.Minute))
+
+	assert.Equal(t, 1, dataStore.count(), "Expired items should not be counted")
+	assert.Equal(t, 1, dataStore.Count())
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "Expired items should be removed")
+}
+
+func TestDataStore_GetKeys_expired(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+
+	assert.Equal(t, []interface{}{"name"}, dataStore.getKeys())
+	assert.Equal(t, []interface{}{"name"}, dataStore.GetKeys())
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "Expired items should be removed")
+}
+
+func TestDataStore_persistentItems(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.Set("name", datatype.NewString("Ivan", 0))
+	dataStore.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	dataStore.Set("age", datatype.NewString("27", 0))
+
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "Items without TTL are stored outside of DeathTime ordering")
+	assert.Equal(t, 3, dataStore.Count())
+	assert.Equal(t, []interface{}{"weight", "age", "name"}, dataStore.GetKeys())
+	assert.Equal(t, 0, dataStore.RemoveExpired(0), "Items without TTL are never expired")
+	value, ok := dataStore.Get("name")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Ivan", value.(datatype.DataType).Value)
+
+	dataStore.Set("name", datatype.NewString("Petr", time.Minute))
+	assert.Equal(t, 2, dataStore.shards[0].cache.Len(), "Item with TTL is moved back into DeathTime ordering")
+	assert.Equal(t, 3, dataStore.Count())
+	assert.Equal(t, true, dataStore.Delete("age"))
+	assert.Equal(t, 2, dataStore.Count())
+	assert.Equal(t, entrySize("name", datatype.NewString("Petr", time.Minute))+entrySize("weight", datatype.NewString("82.5kg", time.Minute)),
+		dataStore.Stats().Memory)
 }
 
 func TestDataStore_compareDataTypesByDeathTime(t *testing.T) {
@@ -246,6 +411,53 @@ func ExampleDataStore_Contains() {
 	fmt.Print(storage.Contains("weight"))
 }
 
+func TestNewDataStore_shards(t *testing.T) {
+	assert.Equal(t, DefaultShards, len(NewDataStore().shards))
+	assert.Equal(t, 4, len(NewDataStore(WithShards(4)).shards))
+	assert.Equal(t, 1, len(NewDataStore(WithShards(4), WithMaxItems(10)).shards), "Bounded collection has a single shard")
+}
+
+func TestDataStore_GetKeys_shards(t *testing.T) {
+	dataStore := NewDataStore(WithShards(8))
+	now := time.Now()
+	var expected []interface{}
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("key %d", i)
+		dataStore.Set(key, datatype.DataType{Value: i, Ttl: time.Hour, DeathTime: now.Add(time.Duration(50-i) * time.Minute)})
+		expected = append([]interface{}{key}, expected...)
+	}
+	dataStore.Set("persistent b", datatype.DataType{Value: "b"})
+	dataStore.Set("persistent a", datatype.DataType{Value: "a"})
+	expected = append(expected, "persistent a", "persistent b")
+
+	assert.Equal(t, expected, dataStore.GetKeys(), "Keys of all shards should be ordered by DeathTime")
+	assert.Equal(t, 52, dataStore.Count())
+	assert.Equal(t, 52, dataStore.Stats().Count)
+}
+
+func TestDataStore_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				dataStore.IncrBy(fmt.Sprintf("counter %d", n%10), 1, 0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	versions := make(map[uint64]bool)
+	for n := 0; n < 10; n++ {
+		value, _ := dataStore.Get(fmt.Sprintf("counter %d", n))
+		assert.Equal(t, int64(80), value.(datatype.DataType).Value)
+		versions[value.(datatype.DataType).Version] = true
+	}
+	assert.Equal(t, 10, len(versions), "Versions should be unique across shards")
+}
+
 func ExampleDataStore_Count() {
 	storage := NewDataStore()
 	storage.Set("name", datatype.NewString("Ivan", time.Minute))
@@ -253,6 +465,21 @@ func ExampleDataStore_Count() {
 	fmt.Println(storage.Count())
 }
 
+func ExampleDataStore_Entries() {
+	storage := NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("age", datatype.NewString("27", time.Minute))
+	for _, entry := range storage.Entries() {
+		fmt.Println(entry.Key, entry.Value.Value)
+	}
+}
+
+func ExampleNewDataStore() {
+	storage := NewDataStore(WithMaxItems(10000), WithMaxMemory(64*1024*1024))
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	fmt.Println(storage.Stats())
+}
+
 func ExampleDataStore_Clear() {
 	storage := NewDataStore()
 	storage.Set("name", datatype.NewString("Ivan", time.Minute))
@@ -335,6 +562,67 @@ func BenchmarkDataStore_BatchDelete(b *testing.B) {
 	}
 }
 
+func BenchmarkDataStore_Set_withMaxItems(b *testing.B) {
+	storage := NewDataStore(WithMaxItems(100))
+
+	for n := 0; n < b.N; n++ {
+		storage.Set(fmt.Sprintf("key %d", n%1000), datatype.NewString("Ivan", time.Minute))
+	}
+}
+
+// BenchmarkDataStore_parallel compares throughput of single shard and sharded collections,
+// run it with -cpu 1,2,4,8 to see how it scales with GOMAXPROCS.
+func BenchmarkDataStore_parallel(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key %d", i)
+	}
+	for _, shards := range []int{1, DefaultShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			storage := NewDataStore(WithShards(shards))
+			for _, key := range keys {
+				storage.Set(key, datatype.NewString("Ivan", time.Minute))
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				n := rand.Int()
+				for pb.Next() {
+					key := keys[n%len(keys)]
+					if n%4 == 0 {
+						storage.Set(key, datatype.NewString("Ivan", time.Minute))
+					} else {
+						storage.Get(key)
+					}
+					n++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkDataStore_parallelSet shows how write throughput scales with shards, run it with -cpu 1,2,4,8.
+// Writes of different shards share no locks while nobody watches the collection.
+func BenchmarkDataStore_parallelSet(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key %d", i)
+	}
+	for _, shards := range []int{1, DefaultShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			storage := NewDataStore(WithShards(shards))
+			value := datatype.NewString("Ivan", time.Minute)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				n := rand.Int()
+				for pb.Next() {
+					storage.Set(keys[n%len(keys)], value)
+					n++
+				}
+			})
+		})
+	}
+}
+
 func BenchmarkDataStore_Clear(b *testing.B) {
 	storage := NewDataStore()
 	storage.Set("name", datatype.NewString("Ivan", time.Minute))
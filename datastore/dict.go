@@ -0,0 +1,167 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"sort"
+	"time"
+)
+
+// Dict operations work with items holding map value, for example created by datatype.NewDict or from json object.
+// Fields are identified by strings, keys of other types are formatted by fmt.
+// Dict is created without TTL when field is set for absent key and removed when its last field is deleted.
+// Dicts are copy-on-write: each mutation stores new map[string]interface{}.
+
+// HGet returns value of the field. Returns false if there is no such field.
+func (ds *DataStore) HGet(key, field string) (interface{}, bool, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, dict, err := s.dict(key)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := dict[field]
+	return value, ok, nil
+}
+
+// HSet sets values of the fields and returns amount of added fields.
+func (ds *DataStore) HSet(key string, fields map[string]interface{}) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, dict, err := s.dict(key)
+	if err != nil {
+		return 0, err
+	}
+	result := copyDict(dict, len(fields))
+	added := 0
+	for field, value := range fields {
+		if _, ok := result[field]; !ok {
+			added++
+		}
+		result[field] = value
+	}
+	s.updateDict(key, item, result)
+	return added, nil
+}
+
+// HDel removes the fields and returns amount of removed fields.
+func (ds *DataStore) HDel(key string, fields ...string) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, dict, err := s.dict(key)
+	if err != nil || dict == nil {
+		return 0, err
+	}
+	result := copyDict(dict, 0)
+	for _, field := range fields {
+		delete(result, field)
+	}
+	removed := len(dict) - len(result)
+	if removed > 0 {
+		s.updateDict(key, item, result)
+	}
+	return removed, nil
+}
+
+// HKeys returns sorted names of the fields.
+func (ds *DataStore) HKeys(key string) ([]string, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, dict, err := s.dict(key)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0, len(dict))
+	for field := range dict {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// HGetAll returns all fields with their values, empty map for absent dict.
+func (ds *DataStore) HGetAll(key string) (map[string]interface{}, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, dict, err := s.dict(key)
+	if err != nil {
+		return nil, err
+	}
+	return copyDict(dict, 0), nil
+}
+
+// HExists returns flag is there such field.
+func (ds *DataStore) HExists(key, field string) (bool, error) {
+	_, ok, err := ds.HGet(key, field)
+	return ok, err
+}
+
+// HIncrBy increments integer value of the field by delta and returns new value. Absent field is treated as zero.
+// Returns ErrNotInteger if the field holds value which is not an integer.
+func (ds *DataStore) HIncrBy(key, field string, delta int64) (int64, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, dict, err := s.dict(key)
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	if value, ok := dict[field]; ok {
+		if current, ok = toInteger(value); !ok {
+			return 0, ErrNotInteger
+		}
+	}
+	sum, ok := addInteger(current, delta)
+	if !ok {
+		return 0, ErrNotInteger
+	}
+	result := copyDict(dict, 1)
+	result[field] = sum
+	s.updateDict(key, item, result)
+	return sum, nil
+}
+
+// dict returns item with the dict stored under the key. Dict is nil when there is no such item.
+// Dict with keys of any type is converted into map[string]interface{}, so it must not be modified.
+func (s *shard) dict(key string) (datatype.DataType, map[string]interface{}, error) {
+	item, ok := s.lookup(key)
+	if !ok || item.IsExpired(time.Now()) {
+		return datatype.DataType{}, nil, nil
+	}
+	switch dict := item.Value.(type) {
+	case map[string]interface{}:
+		return item, dict, nil
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(dict))
+		for field, value := range dict {
+			result[fmt.Sprint(field)] = value
+		}
+		return item, result, nil
+	default:
+		return item, nil, ErrWrongType
+	}
+}
+
+// updateDict saves new dict keeping TTL of the item, empty dict is removed.
+func (s *shard) updateDict(key string, item datatype.DataType, dict map[string]interface{}) {
+	if len(dict) == 0 {
+		s.delete(key)
+		return
+	}
+	item.Value = dict
+	s.set(key, item)
+}
+
+func copyDict(dict map[string]interface{}, extra int) map[string]interface{} {
+	result := make(map[string]interface{}, len(dict)+extra)
+	for field, value := range dict {
+		result[field] = value
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataStore_HSet_HGet(t *testing.T) {
+	dataStore := NewDataStore()
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+
+	added, err := dataStore.HSet("user", map[string]interface{}{"name": "Ivan", "age": 27.0})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, added)
+	added, _ = dataStore.HSet("user", map[string]interface{}{"name": "Petr", "city": "Minsk"})
+	assert.Equal(t, 1, added)
+
+	value, ok, err := dataStore.HGet("user", "name")
+	assert.Nil(t, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Petr", value)
+	_, ok, _ = dataStore.HGet("user", "absent")
+	assert.Equal(t, false, ok)
+	_, ok, _ = dataStore.HGet("absent", "name")
+	assert.Equal(t, false, ok)
+
+	ttl, _ := dataStore.Ttl("user")
+	assert.Equal(t, NoTtl, ttl, "Dict created by HSet has no TTL")
+	assert.Equal(t, "Ivan", operations[0].Value.Value.(map[string]interface{})["name"], "Dicts are copy-on-write")
+}
+
+func TestDataStore_HDel(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("marks", datatype.NewDict(map[interface{}]interface{}{"Math": 9, "English": 7}, time.Minute))
+
+	removed, err := dataStore.HDel("marks", "Math", "absent")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, removed)
+	ttl, _ := dataStore.Ttl("marks")
+	assert.Equal(t, true, ttl > 59*time.Second, "TTL of dict is kept")
+	removed, _ = dataStore.HDel("marks", "English")
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, false, dataStore.Contains("marks"), "Empty dict is removed")
+	removed, _ = dataStore.HDel("marks", "English")
+	assert.Equal(t, 0, removed)
+}
+
+func TestDataStore_HKeys_HGetAll_HExists(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("cards", datatype.NewDict(map[interface{}]interface{}{2: "Visa", 3: "Maestro"}, time.Minute))
+
+	fields, _ := dataStore.HKeys("cards")
+	assert.Equal(t, []string{"2", "3"}, fields)
+	all, _ := dataStore.HGetAll("cards")
+	assert.Equal(t, map[string]interface{}{"2": "Visa", "3": "Maestro"}, all)
+	exists, _ := dataStore.HExists("cards", "3")
+	assert.Equal(t, true, exists)
+	exists, _ = dataStore.HExists("cards", "4")
+	assert.Equal(t, false, exists)
+
+	all, _ = dataStore.HGetAll("absent")
+	assert.Equal(t, map[string]interface{}{}, all)
+	fields, _ = dataStore.HKeys("absent")
+	assert.Equal(t, []string{}, fields)
+}
+
+func TestDataStore_HIncrBy(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.HSet("user", map[string]interface{}{"age": 27.0, "name": "Ivan", "visits": "10"})
+
+	value, err := dataStore.HIncrBy("user", "age", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(28), value)
+	value, _ = dataStore.HIncrBy("user", "visits", -3)
+	assert.Equal(t, int64(7), value)
+	value, _ = dataStore.HIncrBy("user", "logins", 5)
+	assert.Equal(t, int64(5), value, "Absent field is treated as zero")
+	_, err = dataStore.HIncrBy("user", "name", 1)
+	assert.Equal(t, ErrNotInteger, err)
+	dataStore.HSet("user", map[string]interface{}{"weight": 82.5})
+	_, err = dataStore.HIncrBy("user", "weight", 1)
+	assert.Equal(t, ErrNotInteger, err)
+}
+
+func TestDataStore_dict_wrongType(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	_, err := dataStore.HSet("name", map[string]interface{}{"field": "value"})
+	assert.Equal(t, ErrWrongType, err)
+	_, _, err = dataStore.HGet("name", "field")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.HIncrBy("name", "field", 1)
+	assert.Equal(t, ErrWrongType, err)
+}
+
+func TestDataStore_HIncrBy_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dataStore.HIncrBy("counters", "visits", 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _, _ := dataStore.HGet("counters", "visits")
+	assert.Equal(t, int64(1000), value, "Concurrent increments should not be lost")
+}
+
+func ExampleDataStore_HSet() {
+	storage := NewDataStore()
+	storage.HSet("user", map[string]interface{}{"name": "Ivan"})
+	value, _, _ := storage.HGet("user", "name")
+	fmt.Println(value)
+	// Output: Ivan
+}
+
+func BenchmarkDataStore_HIncrBy(b *testing.B) {
+	storage := NewDataStore()
+	storage.HSet("user", map[string]interface{}{"name": "Ivan"})
+
+	for n := 0; n < b.N; n++ {
+		storage.HIncrBy("user", "visits", 1)
+	}
+}
@@ -0,0 +1,21 @@
+package datastore
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when operation requires existing item.
+	ErrNotFound = errors.New("datastore: no such item")
+	// ErrWrongType is returned when operation is applied to item holding value of unsupported type.
+	ErrWrongType = errors.New("datastore: operation against item holding wrong kind of value")
+	// ErrOutOfRange is returned when index is out of range.
+	ErrOutOfRange = errors.New("datastore: index out of range")
+	// ErrNotInteger is returned when increment is applied to value which is not an integer.
+	ErrNotInteger = errors.New("datastore: value is not an integer")
+	// ErrNotFloat is returned when float increment is applied to value which is not a number
+	// or when the result is not a finite number.
+	ErrNotFloat = errors.New("datastore: value is not a valid float")
+	// ErrVersionMismatch is returned by conditional write when the item has other version than expected.
+	ErrVersionMismatch = errors.New("datastore: version mismatch")
+	// ErrWatchedKeyChanged is returned when transaction is aborted because watched item was changed.
+	ErrWatchedKeyChanged = errors.New("datastore: watched key was changed")
+)
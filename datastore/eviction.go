@@ -0,0 +1,167 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/umpc/go-sortedmap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Names of eviction policies supported by EvictionPolicyByName.
+const (
+	PolicyLru         = "lru"
+	PolicyLfu         = "lfu"
+	PolicyTinyLfu     = "tinylfu"
+	PolicyRandom      = "random"
+	PolicyVolatileTtl = "volatile-ttl"
+)
+
+// defaultPolicyCapacity is used to size policies when the collection is limited by memory only.
+const defaultPolicyCapacity = 10000
+
+// EvictionPolicy chooses items to evict when the collection exceeds its size limits.
+// Methods are called under the collection lock, except Touch which could be called concurrently by readers,
+// so implementations must be concurrency-safe.
+type EvictionPolicy interface {
+	// Add is called when key is inserted or updated.
+	Add(key string)
+	// Touch is called when key is read.
+	Touch(key string)
+	// Remove is called when key is removed from the collection.
+	Remove(key string)
+	// Victim returns key which should be evicted next.
+	Victim() (string, bool)
+	// Reset is called when the collection is cleared.
+	Reset()
+}
+
+// boundPolicy is implemented by policies which need access to the collection they are used by.
+type boundPolicy interface {
+	bind(s *shard)
+}
+
+// WithEvictionPolicy sets policy which chooses items to evict when the collection exceeds its limits.
+// LRU policy is used by default.
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	return func(ds *DataStore) {
+		ds.policy = policy
+	}
+}
+
+// EvictionPolicyByName creates eviction policy by its name: "lru", "lfu", "tinylfu", "random" or "volatile-ttl".
+// capacity is an expected maximal amount of items, zero value means that it is unknown.
+func EvictionPolicyByName(name string, capacity int) (EvictionPolicy, error) {
+	if capacity <= 0 {
+		capacity = defaultPolicyCapacity
+	}
+	switch name {
+	case PolicyLru:
+		return NewLruPolicy(), nil
+	case PolicyLfu:
+		return NewLfuPolicy(), nil
+	case PolicyTinyLfu:
+		return NewTinyLfuPolicy(capacity), nil
+	case PolicyRandom:
+		return NewRandomPolicy(), nil
+	case PolicyVolatileTtl:
+		return NewVolatileTtlPolicy(), nil
+	}
+	return nil, fmt.Errorf("datastore: unknown eviction policy %q", name)
+}
+
+// randomPolicy evicts random key.
+type randomPolicy struct {
+	mutex   sync.Mutex
+	keys    []string
+	indexes map[string]int
+	random  *rand.Rand
+}
+
+// NewRandomPolicy creates policy which evicts random items.
+func NewRandomPolicy() EvictionPolicy {
+	return &randomPolicy{
+		indexes: make(map[string]int),
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (p *randomPolicy) Add(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.indexes[key]; !ok {
+		p.indexes[key] = len(p.keys)
+		p.keys = append(p.keys, key)
+	}
+}
+
+func (p *randomPolicy) Touch(key string) {
+}
+
+// Remove replaces removed key by the last one to keep keys slice dense.
+func (p *randomPolicy) Remove(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	index, ok := p.indexes[key]
+	if !ok {
+		return
+	}
+	last := p.keys[len(p.keys)-1]
+	p.keys[index] = last
+	p.indexes[last] = index
+	p.keys = p.keys[:len(p.keys)-1]
+	delete(p.indexes, key)
+}
+
+func (p *randomPolicy) Victim() (string, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.keys) == 0 {
+		return "", false
+	}
+	return p.keys[p.random.Intn(len(p.keys))], true
+}
+
+func (p *randomPolicy) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.keys = nil
+	p.indexes = make(map[string]int)
+}
+
+// volatileTtlPolicy evicts item which expires soonest. It reuses DeathTime ordering of the collection,
+// so it doesn't need to track keys itself. The collection with size limits has a single shard.
+type volatileTtlPolicy struct {
+	s *shard
+}
+
+// NewVolatileTtlPolicy creates policy which evicts items with the nearest DeathTime first.
+func NewVolatileTtlPolicy() EvictionPolicy {
+	return &volatileTtlPolicy{}
+}
+
+func (p *volatileTtlPolicy) bind(s *shard) {
+	p.s = s
+}
+
+func (p *volatileTtlPolicy) Add(key string) {
+}
+
+func (p *volatileTtlPolicy) Touch(key string) {
+}
+
+func (p *volatileTtlPolicy) Remove(key string) {
+}
+
+// Victim returns the first key of the collection ordered by DeathTime. It is called under the collection lock.
+func (p *volatileTtlPolicy) Victim() (string, bool) {
+	victim, ok := "", false
+	p.s.cache.IterFunc(false, func(record sortedmap.Record) bool {
+		victim, ok = record.Key.(string), true
+		return false
+	})
+	return victim, ok
+}
+
+func (p *volatileTtlPolicy) Reset() {
+}
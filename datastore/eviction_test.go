@@ -0,0 +1,125 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEvictionPolicyByName(t *testing.T) {
+	for _, name := range []string{PolicyLru, PolicyLfu, PolicyTinyLfu, PolicyRandom, PolicyVolatileTtl} {
+		policy, err := EvictionPolicyByName(name, 0)
+		assert.Nil(t, err)
+		assert.NotNil(t, policy, "Policy should be created: "+name)
+	}
+
+	_, err := EvictionPolicyByName("fifo", 100)
+	assert.NotNil(t, err)
+}
+
+func TestRandomPolicy(t *testing.T) {
+	p := NewRandomPolicy()
+	_, ok := p.Victim()
+	assert.Equal(t, false, ok, "Empty policy has no victim")
+
+	p.Add("key 1")
+	p.Add("key 2")
+	p.Add("key 2")
+	p.Remove("key 1")
+	p.Remove("absent key")
+	for i := 0; i < 10; i++ {
+		victim, _ := p.Victim()
+		assert.Equal(t, "key 2", victim)
+	}
+
+	p.Reset()
+	_, ok = p.Victim()
+	assert.Equal(t, false, ok, "Reset policy has no victim")
+}
+
+func TestWithEvictionPolicy_lfu(t *testing.T) {
+	dataStore := NewDataStore(WithMaxItems(2), WithEvictionPolicy(NewLfuPolicy()))
+	dataStore.Set("key 1", datatype.NewString("value 1", time.Minute))
+	dataStore.Set("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore.Get("key 1")
+	dataStore.Get("key 1")
+	dataStore.Get("key 2")
+
+	dataStore.Set("key 3", datatype.NewString("value 3", time.Minute))
+	assert.Equal(t, true, dataStore.Contains("key 1"))
+	assert.Equal(t, false, dataStore.Contains("key 2"), "Least frequently used item should be evicted")
+	assert.Equal(t, true, dataStore.Contains("key 3"))
+}
+
+func TestWithEvictionPolicy_tinyLfu(t *testing.T) {
+	dataStore := NewDataStore(WithMaxItems(10), WithEvictionPolicy(NewTinyLfuPolicy(10)))
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("hot %d", i)
+		dataStore.Set(key, datatype.NewString("value", time.Minute))
+		for j := 0; j < 3; j++ {
+			dataStore.Get(key)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		dataStore.Set(fmt.Sprintf("scan %d", i), datatype.NewString("value", time.Minute))
+	}
+	assert.Equal(t, 10, dataStore.Count())
+	hot := 0
+	for i := 0; i < 10; i++ {
+		if dataStore.Contains(fmt.Sprintf("hot %d", i)) {
+			hot++
+		}
+	}
+	assert.Equal(t, true, hot >= 8, "Scan should not flush out popular items")
+}
+
+func TestWithEvictionPolicy_tinyLfuKeepsNewKey(t *testing.T) {
+	for _, maxItems := range []int{1, 3, 100} {
+		dataStore := NewDataStore(WithMaxItems(maxItems), WithEvictionPolicy(NewTinyLfuPolicy(maxItems)))
+		for i := 0; i < 3*maxItems+5; i++ {
+			key := fmt.Sprintf("key %d", i)
+			dataStore.Set(key, datatype.NewString("value", time.Minute))
+			_, ok := dataStore.Get(key)
+			assert.Equal(t, true, ok, "New key should survive its own insert: "+key)
+		}
+		assert.Equal(t, maxItems, dataStore.Count())
+	}
+}
+
+func TestWithEvictionPolicy_volatileTtl(t *testing.T) {
+	dataStore := NewDataStore(WithMaxItems(2), WithEvictionPolicy(NewVolatileTtlPolicy()))
+	dataStore.Set("key 1", datatype.NewString("value 1", 3*time.Minute))
+	dataStore.Set("key 2", datatype.NewString("value 2", time.Minute))
+	dataStore.Set("key 3", datatype.NewString("value 3", 2*time.Minute))
+
+	assert.Equal(t, true, dataStore.Contains("key 1"))
+	assert.Equal(t, false, dataStore.Contains("key 2"), "Item which expires soonest should be evicted")
+	assert.Equal(t, true, dataStore.Contains("key 3"))
+}
+
+func TestWithEvictionPolicy_random(t *testing.T) {
+	dataStore := NewDataStore(WithMaxItems(5), WithEvictionPolicy(NewRandomPolicy()))
+	for i := 0; i < 20; i++ {
+		dataStore.Set(fmt.Sprintf("key %d", i), datatype.NewString("value", time.Minute))
+	}
+	assert.Equal(t, 5, dataStore.Count())
+	assert.Equal(t, int64(15), dataStore.Stats().Evicted)
+}
+
+func ExampleWithEvictionPolicy() {
+	storage := NewDataStore(WithMaxItems(10000), WithEvictionPolicy(NewTinyLfuPolicy(10000)))
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	fmt.Println(storage.Count())
+	// Output: 1
+}
+
+func BenchmarkDataStore_Set_withTinyLfu(b *testing.B) {
+	storage := NewDataStore(WithMaxItems(100), WithEvictionPolicy(NewTinyLfuPolicy(100)))
+
+	for n := 0; n < b.N; n++ {
+		storage.Set(fmt.Sprintf("key %d", n%1000), datatype.NewString("Ivan", time.Minute))
+	}
+}
@@ -0,0 +1,91 @@
+package datastore
+
+import (
+	"time"
+)
+
+// DefaultExpireBatchSize is the maximal amount of expired items removed under one lock acquisition.
+const DefaultExpireBatchSize = 1000
+
+// RemoveExpired removes up to limit expired items, all of them when limit is not positive.
+// Shards are processed one by one, each of them is locked only while its items are removed.
+// Listeners are notified about each removed item. Returns amount of removed items.
+func (ds *DataStore) RemoveExpired(limit int) int {
+	removed := 0
+	for _, s := range ds.shards {
+		batch := 0
+		if limit > 0 {
+			if batch = limit - removed; batch == 0 {
+				break
+			}
+		}
+		removed += s.removeExpired(batch)
+	}
+	return removed
+}
+
+// removeExpired atomically removes up to limit expired items of the shard, all of them when limit is not positive.
+func (s *shard) removeExpired(limit int) int {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	keys := s.expiredKeys(now, limit)
+	s.expire(keys, now)
+	return len(keys)
+}
+
+// StartExpirer starts background removal of expired items with provided interval.
+// Items are removed in batches of batchSize items and the lock is released between batches,
+// so removal of a lot of expired items doesn't stall writers.
+// Already running expirer is stopped. Not positive interval disables background removal.
+func (ds *DataStore) StartExpirer(interval time.Duration, batchSize int) {
+	ds.StopExpirer()
+	if interval <= 0 {
+		return
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultExpireBatchSize
+	}
+	stop := make(chan struct{})
+	ds.expirerMutex.Lock()
+	ds.expirerStop = stop
+	ds.expirerMutex.Unlock()
+
+	ds.expirerDone.Add(1)
+	go func() {
+		defer ds.expirerDone.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ds.removeExpiredBatches(batchSize, stop)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// removeExpiredBatches removes expired items batch by batch until there are no more of them or expirer is stopped.
+func (ds *DataStore) removeExpiredBatches(batchSize int, stop chan struct{}) {
+	for ds.RemoveExpired(batchSize) == batchSize {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+// StopExpirer stops background expirer started by StartExpirer and waits for its completion.
+func (ds *DataStore) StopExpirer() {
+	ds.expirerMutex.Lock()
+	stop := ds.expirerStop
+	ds.expirerStop = nil
+	ds.expirerMutex.Unlock()
+	if stop != nil {
+		close(stop)
+		ds.expirerDone.Wait()
+	}
+}
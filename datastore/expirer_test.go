@@ -0,0 +1,88 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDataStore_RemoveExpired(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	var expired []string
+	dataStore.AddListener(func(operation Operation) {
+		if operation.Type == OperationExpire {
+			expired = append(expired, operation.Key)
+		}
+	})
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+	dataStore.Set("age", datatype.NewString("27", -time.Minute))
+	dataStore.Set("height", datatype.NewString("180cm", -time.Hour))
+
+	assert.Equal(t, 2, dataStore.RemoveExpired(2))
+	assert.Equal(t, []string{"height", "age"}, expired, "Items which expired earlier should be removed first")
+	assert.Equal(t, 1, dataStore.RemoveExpired(0))
+	assert.Equal(t, 0, dataStore.RemoveExpired(0))
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len())
+	assert.Equal(t, dataStore.stats().Memory, entrySize("name", datatype.NewString("Ivan", time.Minute)))
+}
+
+func TestDataStore_StartExpirer(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	for i := 0; i < 25; i++ {
+		dataStore.Set(fmt.Sprintf("key %d", i), datatype.NewString("value", 50*time.Millisecond))
+	}
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	dataStore.StartExpirer(10*time.Millisecond, 10)
+	defer dataStore.StopExpirer()
+	time.Sleep(200 * time.Millisecond)
+
+	dataStore.RLock()
+	defer dataStore.RUnlock()
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "All expired items should be removed in several batches")
+}
+
+func TestDataStore_StopExpirer(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.StopExpirer()
+	dataStore.StartExpirer(time.Millisecond, 0)
+	dataStore.StartExpirer(time.Millisecond, 0)
+	dataStore.StopExpirer()
+	dataStore.Set("weight", datatype.NewString("82.5kg", time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "Stopped expirer should not remove items")
+}
+
+func TestDataStore_StartExpirer_notPositiveInterval(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	dataStore.StartExpirer(time.Millisecond, 0)
+	dataStore.StartExpirer(0, 0)
+	dataStore.StartExpirer(-time.Second, 0)
+	dataStore.Set("weight", datatype.NewString("82.5kg", time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, 1, dataStore.shards[0].cache.Len(), "Not positive interval should disable expirer")
+	dataStore.StopExpirer()
+}
+
+func ExampleDataStore_StartExpirer() {
+	storage := NewDataStore()
+	storage.StartExpirer(time.Second, DefaultExpireBatchSize)
+	defer storage.StopExpirer()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	fmt.Println(storage.Count())
+	// Output: 1
+}
+
+func BenchmarkDataStore_RemoveExpired(b *testing.B) {
+	storage := NewDataStore()
+
+	for n := 0; n < b.N; n++ {
+		storage.Set(fmt.Sprintf("key %d", n), datatype.NewString("value", -time.Second))
+		storage.RemoveExpired(DefaultExpireBatchSize)
+	}
+}
@@ -0,0 +1,124 @@
+package datastore
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lfuBucket holds keys with the same access frequency, most recently used key is the first one.
+type lfuBucket struct {
+	frequency int
+	keys      *list.List
+}
+
+// lfuEntry points to the bucket of the key and to the key inside the bucket.
+type lfuEntry struct {
+	bucket *list.Element
+	key    *list.Element
+}
+
+// lfu tracks access frequency of keys, least frequently used key is the first eviction candidate.
+// Buckets are ordered by frequency, so all operations take constant time.
+// Ties are broken by recency: least recently used key of the lowest frequency is evicted.
+type lfu struct {
+	mutex   sync.Mutex
+	buckets *list.List
+	entries map[string]*lfuEntry
+	newest  string
+}
+
+// NewLfuPolicy creates policy which evicts least frequently used items.
+func NewLfuPolicy() EvictionPolicy {
+	return newLfu()
+}
+
+func newLfu() *lfu {
+	return &lfu{buckets: list.New(), entries: make(map[string]*lfuEntry)}
+}
+
+// Add registers new key with frequency 1. Update of existing key counts as its access.
+func (l *lfu) Add(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if entry, ok := l.entries[key]; ok {
+		l.increment(entry)
+		return
+	}
+	first := l.buckets.Front()
+	if first == nil || first.Value.(*lfuBucket).frequency != 1 {
+		first = l.buckets.PushFront(&lfuBucket{frequency: 1, keys: list.New()})
+	}
+	l.entries[key] = &lfuEntry{bucket: first, key: first.Value.(*lfuBucket).keys.PushFront(key)}
+	l.newest = key
+}
+
+// Touch increments access frequency of the key if it is tracked.
+func (l *lfu) Touch(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if entry, ok := l.entries[key]; ok {
+		l.increment(entry)
+	}
+}
+
+// increment moves key into the bucket with the next frequency.
+func (l *lfu) increment(entry *lfuEntry) {
+	current := entry.bucket.Value.(*lfuBucket)
+	next := entry.bucket.Next()
+	if next == nil || next.Value.(*lfuBucket).frequency != current.frequency+1 {
+		next = l.buckets.InsertAfter(&lfuBucket{frequency: current.frequency + 1, keys: list.New()}, entry.bucket)
+	}
+	key := current.keys.Remove(entry.key)
+	l.removeIfEmpty(entry.bucket)
+	entry.bucket = next
+	entry.key = next.Value.(*lfuBucket).keys.PushFront(key)
+}
+
+func (l *lfu) removeIfEmpty(bucket *list.Element) {
+	if bucket.Value.(*lfuBucket).keys.Len() == 0 {
+		l.buckets.Remove(bucket)
+	}
+}
+
+// Remove stops tracking of the key.
+func (l *lfu) Remove(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if entry, ok := l.entries[key]; ok {
+		entry.bucket.Value.(*lfuBucket).keys.Remove(entry.key)
+		l.removeIfEmpty(entry.bucket)
+		delete(l.entries, key)
+		if key == l.newest {
+			l.newest = ""
+		}
+	}
+}
+
+// Victim returns least recently used key among the least frequently used ones.
+// The newest key is skipped while there are other keys, so it has a chance to accumulate accesses.
+func (l *lfu) Victim() (string, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	first := l.buckets.Front()
+	if first == nil {
+		return "", false
+	}
+	victim := first.Value.(*lfuBucket).keys.Back()
+	if victim.Value.(string) == l.newest {
+		if victim.Prev() != nil {
+			victim = victim.Prev()
+		} else if first.Next() != nil {
+			victim = first.Next().Value.(*lfuBucket).keys.Back()
+		}
+	}
+	return victim.Value.(string), true
+}
+
+// Reset stops tracking of all keys.
+func (l *lfu) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.buckets.Init()
+	l.entries = make(map[string]*lfuEntry)
+	l.newest = ""
+}
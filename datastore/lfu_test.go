@@ -0,0 +1,61 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLfu(t *testing.T) {
+	l := newLfu()
+	_, ok := l.Victim()
+	assert.Equal(t, false, ok, "Empty lfu has no victim")
+
+	l.Add("key 1")
+	l.Add("key 2")
+	l.Add("key 3")
+	victim, _ := l.Victim()
+	assert.Equal(t, "key 1", victim, "Least recently used key wins among keys with the same frequency")
+
+	l.Remove("key 1")
+	l.Remove("key 2")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 3", victim, "The newest key is evicted when it is the only one")
+	l.Add("key 1")
+	l.Add("key 2")
+
+	l.Touch("key 1")
+	l.Touch("key 1")
+	l.Touch("key 2")
+	l.Touch("absent key")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 3", victim)
+
+	l.Add("key 4")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 3", victim, "The newest key is not evicted")
+
+	l.Remove("key 3")
+	l.Remove("key 4")
+	l.Remove("absent key")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 2", victim)
+
+	l.Add("key 2")
+	l.Add("key 2")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 1", victim, "Update counts as access")
+
+	l.Reset()
+	_, ok = l.Victim()
+	assert.Equal(t, false, ok, "Reset lfu has no victim")
+}
+
+func BenchmarkLfu_Touch(b *testing.B) {
+	l := newLfu()
+	l.Add("key 1")
+	l.Add("key 2")
+
+	for n := 0; n < b.N; n++ {
+		l.Touch("key 1")
+	}
+}
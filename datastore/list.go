@@ -0,0 +1,324 @@
+package datastore
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"time"
+)
+
+// List operations work with items holding []interface{} value, for example created by datatype.NewList.
+// List is created without TTL when values are pushed to absent key and removed when its last value is popped.
+// Stored list is a window of listBuffer, so values passed to listeners or returned earlier are never changed,
+// while pushes to either end of the list and pops take amortized constant time.
+
+// LPush inserts values at the head of the list one after another and returns new length of the list.
+func (ds *DataStore) LPush(key string, values ...interface{}) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, buffer, err := s.bufferedList(key)
+	if err != nil {
+		return 0, err
+	}
+	buffer.pushHead(values)
+	s.updateList(key, item, buffer)
+	return buffer.len(), nil
+}
+
+// RPush appends values to the tail of the list and returns new length of the list.
+func (ds *DataStore) RPush(key string, values ...interface{}) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, buffer, err := s.bufferedList(key)
+	if err != nil {
+		return 0, err
+	}
+	buffer.pushTail(values)
+	s.updateList(key, item, buffer)
+	return buffer.len(), nil
+}
+
+// LPop removes and returns the first value of the list. Returns false if the list is empty or absent.
+func (ds *DataStore) LPop(key string) (interface{}, bool, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, buffer, err := s.bufferedList(key)
+	if err != nil || buffer.len() == 0 {
+		return nil, false, err
+	}
+	value := buffer.popHead()
+	s.updateList(key, item, buffer)
+	return value, true, nil
+}
+
+// RPop removes and returns the last value of the list. Returns false if the list is empty or absent.
+func (ds *DataStore) RPop(key string) (interface{}, bool, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, buffer, err := s.bufferedList(key)
+	if err != nil || buffer.len() == 0 {
+		return nil, false, err
+	}
+	value := buffer.popTail()
+	s.updateList(key, item, buffer)
+	return value, true, nil
+}
+
+// LRange returns values of the list between start and stop indexes inclusive.
+// Negative indexes are counted from the end of the list: -1 is the last value.
+func (ds *DataStore) LRange(key string, start, stop int) ([]interface{}, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, list, err := s.list(key)
+	if err != nil {
+		return nil, err
+	}
+	start, stop = normalizeRange(start, stop, len(list))
+	result := make([]interface{}, stop-start)
+	copy(result, list[start:stop])
+	return result, nil
+}
+
+// LTrim leaves only values of the list between start and stop indexes inclusive.
+// The list is removed when no values are left.
+func (ds *DataStore) LTrim(key string, start, stop int) error {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, buffer, err := s.bufferedList(key)
+	if err != nil || buffer.len() == 0 {
+		return err
+	}
+	start, stop = normalizeRange(start, stop, buffer.len())
+	if start == 0 && stop == buffer.len() {
+		return nil
+	}
+	buffer.trim(start, stop)
+	s.updateList(key, item, buffer)
+	return nil
+}
+
+// LIndex returns value of the list with provided index. Returns false if index is out of range.
+func (ds *DataStore) LIndex(key string, index int) (interface{}, bool, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, list, err := s.list(key)
+	if err != nil {
+		return nil, false, err
+	}
+	index, ok := normalizeIndex(index, len(list))
+	if !ok {
+		return nil, false, nil
+	}
+	return list[index], true, nil
+}
+
+// LSet replaces value of the list with provided index.
+// Returns ErrNotFound if there is no such list and ErrOutOfRange if index is out of range.
+func (ds *DataStore) LSet(key string, index int, value interface{}) error {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, buffer, err := s.bufferedList(key)
+	if err != nil {
+		return err
+	}
+	if buffer.len() == 0 {
+		return ErrNotFound
+	}
+	index, ok := normalizeIndex(index, buffer.len())
+	if !ok {
+		return ErrOutOfRange
+	}
+	buffer.set(index, value)
+	s.updateList(key, item, buffer)
+	return nil
+}
+
+// LLen returns length of the list, zero for absent list.
+func (ds *DataStore) LLen(key string) (int, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, list, err := s.list(key)
+	return len(list), err
+}
+
+// list returns item with the list stored under the key. List is nil when there is no such item.
+func (s *shard) list(key string) (datatype.DataType, []interface{}, error) {
+	item, ok := s.lookup(key)
+	if !ok || item.IsExpired(time.Now()) {
+		return datatype.DataType{}, nil, nil
+	}
+	list, ok := item.Value.([]interface{})
+	if !ok {
+		return item, nil, ErrWrongType
+	}
+	return item, list, nil
+}
+
+// bufferedList returns item with backing buffer of the list stored under the key.
+// Buffer is empty when there is no such item.
+func (s *shard) bufferedList(key string) (datatype.DataType, *listBuffer, error) {
+	item, list, err := s.list(key)
+	if err != nil {
+		return item, nil, err
+	}
+	if buffer, ok := s.lists[key]; ok && list != nil {
+		return item, buffer, nil
+	}
+	return item, newListBuffer(list), nil
+}
+
+// updateList saves the list from buffer keeping TTL of the item, empty list is removed.
+func (s *shard) updateList(key string, item datatype.DataType, buffer *listBuffer) {
+	if buffer.len() == 0 {
+		s.delete(key)
+		return
+	}
+	item.Value = buffer.list()
+	if s.lists[key] == buffer && s.itemSize == nil {
+		// stored list is the previous window of the buffer, so its size is known without estimating the list again
+		s.store(key, item, buffer.size-buffer.storedSize)
+	} else {
+		s.set(key, item)
+	}
+	buffer.storedSize = buffer.size
+	if _, ok := s.lookup(key); ok {
+		s.lists[key] = buffer
+	}
+}
+
+// listBuffer is a backing array of the list with free space at both ends, the list is values[head:tail].
+// Values outside of [low, high) were never stored, so pushes write there without copying the list.
+// Values inside of it could be still seen by readers, so pushes after pops from the same end
+// and LSet copy the list into new array.
+type listBuffer struct {
+	values     []interface{}
+	head, tail int
+	low, high  int
+
+	// size is the sum of estimated sizes of the list values, storedSize is the one of the stored list
+	size       int64
+	storedSize int64
+}
+
+// newListBuffer creates buffer without free space, so list created by other operations is never changed.
+func newListBuffer(list []interface{}) *listBuffer {
+	buffer := &listBuffer{values: list[:len(list):len(list)], tail: len(list), high: len(list)}
+	for _, value := range list {
+		buffer.size += datatype.EstimateListValueSize(value)
+	}
+	return buffer
+}
+
+func (b *listBuffer) len() int {
+	return b.tail - b.head
+}
+
+// list returns the list which capacity is limited by its length, so appending to it never changes the buffer.
+func (b *listBuffer) list() []interface{} {
+	return b.values[b.head:b.tail:b.tail]
+}
+
+// pushHead inserts values at the head of the list one after another.
+func (b *listBuffer) pushHead(values []interface{}) {
+	if b.head != b.low || b.head < len(values) {
+		b.grow(len(values), 0)
+	}
+	for _, value := range values {
+		b.head--
+		b.values[b.head] = value
+		b.size += datatype.EstimateListValueSize(value)
+	}
+	b.low = b.head
+}
+
+// pushTail appends values to the tail of the list.
+func (b *listBuffer) pushTail(values []interface{}) {
+	if b.tail != b.high || len(b.values)-b.tail < len(values) {
+		b.grow(0, len(values))
+	}
+	for _, value := range values {
+		b.values[b.tail] = value
+		b.tail++
+		b.size += datatype.EstimateListValueSize(value)
+	}
+	b.high = b.tail
+}
+
+func (b *listBuffer) popHead() interface{} {
+	value := b.values[b.head]
+	b.head++
+	b.size -= datatype.EstimateListValueSize(value)
+	return value
+}
+
+func (b *listBuffer) popTail() interface{} {
+	b.tail--
+	value := b.values[b.tail]
+	b.size -= datatype.EstimateListValueSize(value)
+	return value
+}
+
+// trim leaves only values between start and stop indexes of the list, stop is exclusive.
+func (b *listBuffer) trim(start, stop int) {
+	for b.len() > stop {
+		b.popTail()
+	}
+	for i := 0; i < start; i++ {
+		b.popHead()
+	}
+}
+
+// set replaces value of the list with provided index, the list is copied because the old value could be seen by readers.
+func (b *listBuffer) set(index int, value interface{}) {
+	b.grow(0, 0)
+	b.size += datatype.EstimateListValueSize(value) - datatype.EstimateListValueSize(b.values[b.head+index])
+	b.values[b.head+index] = value
+}
+
+// grow copies the list into new array with at least front and back free values at the ends.
+// Both ends get extra free space proportional to length of the list, so pushes copy it rarely.
+func (b *listBuffer) grow(front, back int) {
+	length := b.len()
+	front += length / 2
+	back += length / 2
+	values := make([]interface{}, front+length+back)
+	copy(values[front:], b.values[b.head:b.tail])
+	b.values = values
+	b.head, b.tail = front, front+length
+	b.low, b.high = b.head, b.tail
+}
+
+// normalizeIndex converts negative index into positive one and checks that it is in range.
+func normalizeIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
+
+// normalizeRange converts inclusive range with possibly negative indexes into slice bounds.
+func normalizeRange(start, stop, length int) (int, int) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop {
+		return 0, 0
+	}
+	return start, stop + 1
+}
@@ -0,0 +1,206 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataStore_LPush_RPush(t *testing.T) {
+	dataStore := NewDataStore()
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+
+	length, err := dataStore.RPush("phones", "Xiaomi", "Samsung")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, length)
+	length, _ = dataStore.LPush("phones", "Nokia", "Apple")
+	assert.Equal(t, 4, length)
+	values, _ := dataStore.LRange("phones", 0, -1)
+	assert.Equal(t, []interface{}{"Apple", "Nokia", "Xiaomi", "Samsung"}, values)
+
+	ttl, _ := dataStore.Ttl("phones")
+	assert.Equal(t, NoTtl, ttl, "List created by push has no TTL")
+	assert.Equal(t, OperationSet, operations[1].Type)
+	assert.Equal(t, values, operations[1].Value.Value, "Listeners receive resulting list")
+	assert.Equal(t, []interface{}{"Xiaomi", "Samsung"}, operations[0].Value.Value, "Values passed to listeners are never changed")
+}
+
+func TestDataStore_RPush_keepsTtl(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("phones", datatype.NewList([]interface{}{"Xiaomi"}, time.Minute))
+
+	dataStore.RPush("phones", "Samsung")
+	ttl, _ := dataStore.Ttl("phones")
+	assert.Equal(t, true, ttl > 59*time.Second)
+}
+
+func TestDataStore_LPop_RPop(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.RPush("phones", "Xiaomi", "Samsung", "Nokia")
+
+	value, ok, err := dataStore.LPop("phones")
+	assert.Nil(t, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Xiaomi", value)
+	value, _, _ = dataStore.RPop("phones")
+	assert.Equal(t, "Nokia", value)
+	value, _, _ = dataStore.RPop("phones")
+	assert.Equal(t, "Samsung", value)
+	assert.Equal(t, false, dataStore.Contains("phones"), "Empty list is removed")
+
+	_, ok, err = dataStore.LPop("phones")
+	assert.Nil(t, err)
+	assert.Equal(t, false, ok)
+}
+
+func TestDataStore_LRange(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.RPush("numbers", 0, 1, 2, 3, 4)
+
+	values, _ := dataStore.LRange("numbers", 1, 2)
+	assert.Equal(t, []interface{}{1, 2}, values)
+	values, _ = dataStore.LRange("numbers", -2, -1)
+	assert.Equal(t, []interface{}{3, 4}, values)
+	values, _ = dataStore.LRange("numbers", -100, 100)
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, values)
+	values, _ = dataStore.LRange("numbers", 3, 1)
+	assert.Equal(t, []interface{}{}, values)
+	values, _ = dataStore.LRange("absent", 0, -1)
+	assert.Equal(t, []interface{}{}, values)
+}
+
+func TestDataStore_LTrim(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.RPush("numbers", 0, 1, 2, 3, 4)
+
+	assert.Nil(t, dataStore.LTrim("numbers", 1, -2))
+	values, _ := dataStore.LRange("numbers", 0, -1)
+	assert.Equal(t, []interface{}{1, 2, 3}, values)
+	assert.Nil(t, dataStore.LTrim("numbers", 5, 10))
+	assert.Equal(t, false, dataStore.Contains("numbers"), "Empty list is removed")
+	assert.Nil(t, dataStore.LTrim("absent", 0, 1))
+}
+
+func TestDataStore_LIndex_LSet_LLen(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.RPush("phones", "Xiaomi", "Samsung")
+
+	value, ok, _ := dataStore.LIndex("phones", -1)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Samsung", value)
+	_, ok, _ = dataStore.LIndex("phones", 2)
+	assert.Equal(t, false, ok)
+
+	assert.Nil(t, dataStore.LSet("phones", 0, "Nokia"))
+	value, _, _ = dataStore.LIndex("phones", 0)
+	assert.Equal(t, "Nokia", value)
+	assert.Equal(t, ErrOutOfRange, dataStore.LSet("phones", 5, "Nokia"))
+	assert.Equal(t, ErrNotFound, dataStore.LSet("absent", 0, "Nokia"))
+
+	length, _ := dataStore.LLen("phones")
+	assert.Equal(t, 2, length)
+	length, _ = dataStore.LLen("absent")
+	assert.Equal(t, 0, length)
+}
+
+func TestDataStore_list_wrongType(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	_, err := dataStore.RPush("name", "value")
+	assert.Equal(t, ErrWrongType, err)
+	_, _, err = dataStore.LPop("name")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.LRange("name", 0, -1)
+	assert.Equal(t, ErrWrongType, err)
+	assert.Equal(t, ErrWrongType, dataStore.LSet("name", 0, "value"))
+}
+
+func TestDataStore_RPush_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(producer int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dataStore.RPush("queue", fmt.Sprintf("%d-%d", producer, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	length, _ := dataStore.LLen("queue")
+	assert.Equal(t, 1000, length, "Concurrent producers should not lose values")
+}
+
+func TestDataStore_list_keepsStoredValues(t *testing.T) {
+	dataStore := NewDataStore()
+	owned := make([]interface{}, 2, 10)
+	owned[0], owned[1] = "Xiaomi", "Samsung"
+	dataStore.Set("phones", datatype.NewList(owned, 0))
+
+	dataStore.RPush("phones", "Nokia")
+	assert.Equal(t, []interface{}{"Xiaomi", "Samsung", nil}, owned[:3], "Capacity of stored slice is not used")
+
+	dataStore.RPush("phones", "Apple")
+	value, _ := dataStore.Get("phones")
+	dataStore.LPop("phones")
+	dataStore.RPop("phones")
+	dataStore.LPush("phones", "Huawei")
+	dataStore.RPush("phones", "Sony")
+	dataStore.LSet("phones", 1, "Motorola")
+	assert.Equal(t, []interface{}{"Xiaomi", "Samsung", "Nokia", "Apple"}, value.(datatype.DataType).Value, "Values returned earlier are never changed")
+	values, _ := dataStore.LRange("phones", 0, -1)
+	assert.Equal(t, []interface{}{"Huawei", "Motorola", "Nokia", "Sony"}, values)
+	value, _ = dataStore.Get("phones")
+	assert.Equal(t, entrySize("phones", value), dataStore.Stats().Memory, "Size of the list is tracked by pushes and pops")
+}
+
+func ExampleDataStore_RPush() {
+	storage := NewDataStore()
+	storage.RPush("phones", "Xiaomi", "Samsung")
+	values, _ := storage.LRange("phones", 0, -1)
+	fmt.Println(values)
+	// Output: [Xiaomi Samsung]
+}
+
+func BenchmarkDataStore_RPush_LPop(b *testing.B) {
+	storage := NewDataStore()
+	storage.RPush("queue", "first", "second")
+
+	for n := 0; n < b.N; n++ {
+		storage.RPush("queue", "value")
+		storage.LPop("queue")
+	}
+}
+
+func BenchmarkDataStore_RPush_longList(b *testing.B) {
+	storage := NewDataStore()
+	for i := 0; i < 100000; i++ {
+		storage.RPush("queue", i)
+	}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		storage.RPush("queue", n)
+	}
+}
+
+func BenchmarkDataStore_LPush_longList(b *testing.B) {
+	storage := NewDataStore()
+	for i := 0; i < 100000; i++ {
+		storage.LPush("stack", i)
+	}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		storage.LPush("stack", n)
+	}
+}
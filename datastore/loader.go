@@ -0,0 +1,161 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"time"
+)
+
+// DefaultLoadErrorTtl is the time during which error returned by Loader is returned for the key without new load.
+const DefaultLoadErrorTtl = time.Second
+
+// Loader loads value of absent item, for example from database, and returns it with TTL of the item.
+// Zero TTL means that loaded item never expires.
+type Loader func(ctx context.Context) (interface{}, time.Duration, error)
+
+// load is a call of Loader shared by all callers of GetOrLoad waiting for the same key.
+type load struct {
+	done    chan struct{}
+	item    datatype.DataType
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// WithLoadErrorTtl sets the time during which error returned by Loader is returned for the key without new load,
+// zero value disables caching of errors. DefaultLoadErrorTtl is used by default. Panics if ttl is negative.
+func WithLoadErrorTtl(ttl time.Duration) Option {
+	if ttl < 0 {
+		panic("datastore: negative load error TTL")
+	}
+	return func(ds *DataStore) {
+		ds.loadErrorTtl = ttl
+	}
+}
+
+// GetOrLoad returns item stored under the key. Absent item is loaded by loader and stored with TTL returned by it.
+// Concurrent calls for the same key share one call of loader, so a cold key under load causes one backend request.
+// Error of loader is returned to all of them and to subsequent calls during load error TTL, see WithLoadErrorTtl.
+//
+// Caller stops waiting when its ctx is done. Loader is called with context which keeps values of ctx of the first
+// caller and is cancelled when all callers stopped waiting. Item saved under the key during load is not overwritten.
+// GetOrLoad must not be called from Transaction.
+func (ds *DataStore) GetOrLoad(ctx context.Context, key string, loader Loader) (datatype.DataType, error) {
+	if value, ok := ds.Get(key); ok {
+		return value.(datatype.DataType), nil
+	}
+
+	ds.loadMutex.Lock()
+	l, ok := ds.loads[key]
+	if ok && isClosed(l.done) {
+		// completed load is kept only to return its error
+		ds.loadMutex.Unlock()
+		return datatype.DataType{}, l.err
+	}
+	if !ok {
+		// load could finish and unregister after the check above, its item is found under loadMutex
+		if value, found := ds.Get(key); found {
+			ds.loadMutex.Unlock()
+			return value.(datatype.DataType), nil
+		}
+		loadCtx, cancel := context.WithCancel(detachedContext{ctx})
+		l = &load{done: make(chan struct{}), cancel: cancel}
+		ds.loads[key] = l
+		go ds.load(loadCtx, key, l, loader)
+	}
+	l.waiters++
+	ds.loadMutex.Unlock()
+
+	select {
+	case <-l.done:
+		return l.item, l.err
+	case <-ctx.Done():
+		ds.loadMutex.Lock()
+		defer ds.loadMutex.Unlock()
+		l.waiters--
+		if l.waiters == 0 && ds.loads[key] == l {
+			// abandoned load is cancelled, next caller starts a new one
+			l.cancel()
+			delete(ds.loads, key)
+		}
+		return datatype.DataType{}, ctx.Err()
+	}
+}
+
+// load calls loader, stores its result and passes it to waiters of l.
+func (ds *DataStore) load(ctx context.Context, key string, l *load, loader Loader) {
+	item, err := callLoader(ctx, loader)
+	if err == nil {
+		if stored, ok := ds.SetNX(key, item); ok {
+			item = stored
+		} else if value, ok := ds.Get(key); ok {
+			item = value.(datatype.DataType)
+		}
+	}
+
+	ds.loadMutex.Lock()
+	defer ds.loadMutex.Unlock()
+	l.item, l.err = item, err
+	close(l.done)
+	isAbandoned := ctx.Err() != nil
+	l.cancel()
+	if ds.loads[key] != l {
+		return
+	}
+	if err == nil || isAbandoned || ds.loadErrorTtl == 0 {
+		delete(ds.loads, key)
+		return
+	}
+	time.AfterFunc(ds.loadErrorTtl, func() {
+		ds.loadMutex.Lock()
+		defer ds.loadMutex.Unlock()
+		if ds.loads[key] == l {
+			delete(ds.loads, key)
+		}
+	})
+}
+
+// callLoader converts result of loader into item, panic of loader is returned as error.
+func callLoader(ctx context.Context, loader Loader) (item datatype.DataType, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("datastore: loader panicked: %v", r)
+		}
+	}()
+	value, ttl, err := loader(ctx)
+	if err != nil {
+		return datatype.DataType{}, err
+	}
+	return datatype.DataType{Value: value, Ttl: ttl, DeathTime: datatype.DeathTimeOf(ttl)}, nil
+}
+
+func isClosed(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// detachedContext keeps values of its parent, but it is never cancelled with the parent.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
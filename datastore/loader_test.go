@@ -0,0 +1,227 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDataStore_GetOrLoad(t *testing.T) {
+	dataStore := NewDataStore()
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		calls++
+		return "Ivan", time.Minute, nil
+	}
+
+	item, err := dataStore.GetOrLoad(context.Background(), "name", loader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Ivan", item.Value)
+	assert.Equal(t, time.Minute, item.Ttl)
+	assert.NotZero(t, item.Version)
+
+	item, err = dataStore.GetOrLoad(context.Background(), "name", loader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Ivan", item.Value)
+	assert.Equal(t, 1, calls, "Stored item should be returned without load")
+	ttl, _ := dataStore.Ttl("name")
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+}
+
+func TestDataStore_GetOrLoad_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	var calls int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "Ivan", 0, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan interface{}, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, _ := dataStore.GetOrLoad(context.Background(), "name", loader)
+			results <- item.Value
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Concurrent callers should share one load")
+	for value := range results {
+		assert.Equal(t, "Ivan", value)
+	}
+}
+
+func TestDataStore_GetOrLoad_lateCaller(t *testing.T) {
+	dataStore := NewDataStore()
+	var calls int32
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return "Petr", 0, nil
+	}
+
+	// late caller misses the item and waits for loadMutex while a finishing load stores it and unregisters
+	dataStore.loadMutex.Lock()
+	result := make(chan datatype.DataType)
+	go func() {
+		item, _ := dataStore.GetOrLoad(context.Background(), "name", loader)
+		result <- item
+	}()
+	time.Sleep(20 * time.Millisecond)
+	dataStore.Set("name", datatype.NewString("Ivan", 0))
+	dataStore.loadMutex.Unlock()
+
+	assert.Equal(t, "Ivan", (<-result).Value, "Stored item should be returned")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls), "Stored item should not be loaded again")
+}
+
+func TestWithLoadErrorTtl_negative(t *testing.T) {
+	assert.Panics(t, func() {
+		NewDataStore(WithLoadErrorTtl(-time.Second))
+	})
+}
+
+func TestDataStore_GetOrLoad_error(t *testing.T) {
+	dataStore := NewDataStore(WithLoadErrorTtl(50 * time.Millisecond))
+	failure := errors.New("database is down")
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		calls++
+		if calls == 1 {
+			return nil, 0, failure
+		}
+		return "Ivan", 0, nil
+	}
+
+	_, err := dataStore.GetOrLoad(context.Background(), "name", loader)
+	assert.Equal(t, failure, err)
+	_, err = dataStore.GetOrLoad(context.Background(), "name", loader)
+	assert.Equal(t, failure, err, "Error should be cached")
+	assert.Equal(t, 1, calls)
+	assert.False(t, dataStore.Contains("name"), "Error should not be stored as item")
+
+	time.Sleep(100 * time.Millisecond)
+	item, err := dataStore.GetOrLoad(context.Background(), "name", loader)
+	assert.Nil(t, err, "Item should be loaded again after load error TTL")
+	assert.Equal(t, "Ivan", item.Value)
+}
+
+func TestDataStore_GetOrLoad_errorNotCached(t *testing.T) {
+	dataStore := NewDataStore(WithLoadErrorTtl(0))
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		calls++
+		return nil, 0, errors.New("database is down")
+	}
+
+	dataStore.GetOrLoad(context.Background(), "name", loader)
+	dataStore.GetOrLoad(context.Background(), "name", loader)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDataStore_GetOrLoad_cancel(t *testing.T) {
+	dataStore := NewDataStore()
+	cancelled := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, 0, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := dataStore.GetOrLoad(ctx, "name", loader)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Loader should be cancelled when nobody waits for it")
+	}
+
+	item, err := dataStore.GetOrLoad(context.Background(), "name", func(ctx context.Context) (interface{}, time.Duration, error) {
+		return "Ivan", 0, nil
+	})
+	assert.Nil(t, err, "Cancelled load should not be cached")
+	assert.Equal(t, "Ivan", item.Value)
+}
+
+func TestDataStore_GetOrLoad_cancelOneWaiter(t *testing.T) {
+	dataStore := NewDataStore()
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		select {
+		case <-release:
+			return "Ivan", 0, nil
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+
+	result := make(chan error)
+	go func() {
+		_, err := dataStore.GetOrLoad(context.Background(), "name", loader)
+		result <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := dataStore.GetOrLoad(ctx, "name", loader)
+	assert.Equal(t, context.Canceled, err)
+
+	close(release)
+	assert.Nil(t, <-result, "Load should continue while somebody waits for it")
+	assert.True(t, dataStore.Contains("name"))
+}
+
+func TestDataStore_GetOrLoad_keepsConcurrentSet(t *testing.T) {
+	dataStore := NewDataStore()
+	item, err := dataStore.GetOrLoad(context.Background(), "name", func(ctx context.Context) (interface{}, time.Duration, error) {
+		dataStore.Set("name", datatype.NewString("Petr", 0))
+		return "Ivan", 0, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Petr", item.Value, "Item saved during load should not be overwritten")
+}
+
+func TestDataStore_GetOrLoad_panic(t *testing.T) {
+	dataStore := NewDataStore()
+	_, err := dataStore.GetOrLoad(context.Background(), "name", func(ctx context.Context) (interface{}, time.Duration, error) {
+		panic("boom")
+	})
+	assert.EqualError(t, err, "datastore: loader panicked: boom")
+}
+
+func ExampleDataStore_GetOrLoad() {
+	storage := NewDataStore()
+	item, err := storage.GetOrLoad(context.Background(), "name", func(ctx context.Context) (interface{}, time.Duration, error) {
+		return "Ivan", time.Minute, nil
+	})
+	fmt.Println(item.Value, err)
+	// Output: Ivan <nil>
+}
+
+func BenchmarkDataStore_GetOrLoad(b *testing.B) {
+	storage := NewDataStore()
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		return "Ivan", time.Minute, nil
+	}
+
+	for n := 0; n < b.N; n++ {
+		storage.GetOrLoad(context.Background(), "name", loader)
+	}
+}
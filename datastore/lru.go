@@ -0,0 +1,71 @@
+package datastore
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru tracks order in which keys were accessed, least recently used key is the first eviction candidate.
+type lru struct {
+	mutex    sync.Mutex
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+// NewLruPolicy creates policy which evicts least recently used items.
+func NewLruPolicy() EvictionPolicy {
+	return newLru()
+}
+
+func newLru() *lru {
+	return &lru{order: list.New(), elements: make(map[string]*list.Element)}
+}
+
+// Add registers key as the most recently used one.
+func (l *lru) Add(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if element, ok := l.elements[key]; ok {
+		l.order.MoveToFront(element)
+		return
+	}
+	l.elements[key] = l.order.PushFront(key)
+}
+
+// Touch marks key as the most recently used one if it is tracked.
+func (l *lru) Touch(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if element, ok := l.elements[key]; ok {
+		l.order.MoveToFront(element)
+	}
+}
+
+// Remove stops tracking of the key.
+func (l *lru) Remove(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if element, ok := l.elements[key]; ok {
+		l.order.Remove(element)
+		delete(l.elements, key)
+	}
+}
+
+// Victim returns least recently used key.
+func (l *lru) Victim() (string, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	element := l.order.Back()
+	if element == nil {
+		return "", false
+	}
+	return element.Value.(string), true
+}
+
+// Reset stops tracking of all keys.
+func (l *lru) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.order.Init()
+	l.elements = make(map[string]*list.Element)
+}
@@ -0,0 +1,43 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLru(t *testing.T) {
+	l := newLru()
+	_, ok := l.Victim()
+	assert.Equal(t, false, ok, "Empty lru has no victim")
+
+	l.Add("key 1")
+	l.Add("key 2")
+	l.Add("key 3")
+	victim, _ := l.Victim()
+	assert.Equal(t, "key 1", victim)
+
+	l.Touch("key 1")
+	l.Touch("absent key")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 2", victim)
+
+	l.Add("key 2")
+	l.Remove("key 3")
+	l.Remove("absent key")
+	victim, _ = l.Victim()
+	assert.Equal(t, "key 1", victim)
+
+	l.Reset()
+	_, ok = l.Victim()
+	assert.Equal(t, false, ok, "Reset lru has no victim")
+}
+
+func BenchmarkLru_Touch(b *testing.B) {
+	l := newLru()
+	l.Add("key 1")
+	l.Add("key 2")
+
+	for n := 0; n < b.N; n++ {
+		l.Touch("key 1")
+	}
+}
@@ -0,0 +1,52 @@
+package datastore
+
+import "github.com/andrei-punko/go-cache/datatype"
+
+// OperationType is a type of mutation applied to the collection.
+type OperationType int
+
+const (
+	// OperationSet means that Value was saved under Key.
+	OperationSet OperationType = iota + 1
+	// OperationDelete means that Key was deleted.
+	OperationDelete
+	// OperationBatchDelete means that Keys were deleted.
+	OperationBatchDelete
+	// OperationClear means that all items were removed.
+	OperationClear
+	// OperationEvict means that Key was evicted because the collection exceeded its size limits.
+	OperationEvict
+	// OperationExpire means that Key was removed because its DeathTime passed.
+	OperationExpire
+	// OperationSlide means that DeathTime of sliding item Key was pushed forward to Value.DeathTime by read.
+	// It is not a change of the item, so it is not reported to watchers.
+	OperationSlide
+)
+
+// Operation describes a mutation applied to the collection.
+type Operation struct {
+	Type  OperationType
+	Key   string
+	Keys  []string
+	Value datatype.DataType
+}
+
+// Listener is notified about each mutation of the collection in the order they are applied to the key.
+// Listener is called under the lock of the shard of the key, so it should be fast and must not call DataStore methods.
+// Mutations of keys of different shards are applied concurrently, so listener must be concurrency-safe.
+type Listener func(operation Operation)
+
+// AddListener registers listener which will be notified about all subsequent mutations.
+func (ds *DataStore) AddListener(listener Listener) {
+	ds.Lock()
+	defer ds.Unlock()
+	ds.listeners = append(ds.listeners, listener)
+}
+
+// notify passes operation to all registered listeners and watchers. Should be called under the lock of a shard.
+func (h *hub) notify(operation Operation) {
+	for _, listener := range h.listeners {
+		listener(operation)
+	}
+	h.notifyWatchers(operation)
+}
@@ -0,0 +1,53 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDataStore_AddListener(t *testing.T) {
+	dataStore := NewDataStore()
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+	value := datatype.NewString("value 1", time.Minute)
+
+	stored1 := dataStore.Set("key 1", value)
+	stored2 := dataStore.Set("key 2", value)
+	stored3 := dataStore.Set("key 3", value)
+	dataStore.Delete("key 1")
+	dataStore.Delete("absent key")
+	dataStore.BatchDelete([]interface{}{"key 2", "absent key"})
+	dataStore.BatchDelete([]interface{}{"absent key"})
+	dataStore.Clear()
+
+	assert.Equal(t, []Operation{
+		{Type: OperationSet, Key: "key 1", Value: stored1},
+		{Type: OperationSet, Key: "key 2", Value: stored2},
+		{Type: OperationSet, Key: "key 3", Value: stored3},
+		{Type: OperationDelete, Key: "key 1"},
+		{Type: OperationBatchDelete, Keys: []string{"key 2"}},
+		{Type: OperationClear},
+	}, operations)
+}
+
+func ExampleDataStore_AddListener() {
+	storage := NewDataStore()
+	storage.AddListener(func(operation Operation) {
+		fmt.Println(operation.Type, operation.Key)
+	})
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+}
+
+func BenchmarkDataStore_Set_withListener(b *testing.B) {
+	storage := NewDataStore()
+	storage.AddListener(func(operation Operation) {})
+
+	for n := 0; n < b.N; n++ {
+		storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	}
+}
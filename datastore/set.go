@@ -0,0 +1,235 @@
+package datastore
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"time"
+)
+
+// Set operations work with items holding datatype.Set value.
+// Set is created without TTL when members are added to absent key and removed when its last member is removed.
+// Sets are copy-on-write: each mutation stores new datatype.Set.
+
+// setOperation is an operation of set algebra.
+type setOperation int
+
+const (
+	setUnion setOperation = iota
+	setIntersection
+	setDifference
+)
+
+// SAdd adds members to the set and returns amount of members which were not present in the set.
+func (ds *DataStore) SAdd(key string, members ...string) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, set, err := s.members(key)
+	if err != nil {
+		return 0, err
+	}
+	result := copySet(set, len(members))
+	for _, member := range members {
+		result[member] = true
+	}
+	added := len(result) - len(set)
+	if added > 0 {
+		s.updateSet(key, item, result)
+	}
+	return added, nil
+}
+
+// SRem removes members from the set and returns amount of removed members.
+func (ds *DataStore) SRem(key string, members ...string) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	item, set, err := s.members(key)
+	if err != nil || set == nil {
+		return 0, err
+	}
+	result := copySet(set, 0)
+	for _, member := range members {
+		delete(result, member)
+	}
+	removed := len(set) - len(result)
+	if removed > 0 {
+		s.updateSet(key, item, result)
+	}
+	return removed, nil
+}
+
+// SIsMember returns flag is the member present in the set.
+func (ds *DataStore) SIsMember(key, member string) (bool, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.members(key)
+	return set.Contains(member), err
+}
+
+// SMembers returns sorted members of the set, empty slice for absent set.
+func (ds *DataStore) SMembers(key string) ([]string, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.members(key)
+	if err != nil {
+		return nil, err
+	}
+	return set.Members(), nil
+}
+
+// SCard returns amount of members of the set, zero for absent set.
+func (ds *DataStore) SCard(key string) (int, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.members(key)
+	return len(set), err
+}
+
+// SUnion returns sorted members present in any of the sets.
+func (ds *DataStore) SUnion(keys ...string) ([]string, error) {
+	return ds.combineMembers(setUnion, keys)
+}
+
+// SInter returns sorted members present in all the sets.
+func (ds *DataStore) SInter(keys ...string) ([]string, error) {
+	return ds.combineMembers(setIntersection, keys)
+}
+
+// SDiff returns sorted members of the first set which are absent in all other sets.
+func (ds *DataStore) SDiff(keys ...string) ([]string, error) {
+	return ds.combineMembers(setDifference, keys)
+}
+
+// SUnionStore saves union of the sets under destination key and returns amount of its members.
+func (ds *DataStore) SUnionStore(destination string, keys ...string) (int, error) {
+	return ds.combineStore(setUnion, destination, keys)
+}
+
+// SInterStore saves intersection of the sets under destination key and returns amount of its members.
+func (ds *DataStore) SInterStore(destination string, keys ...string) (int, error) {
+	return ds.combineStore(setIntersection, destination, keys)
+}
+
+// SDiffStore saves difference of the first set and other sets under destination key
+// and returns amount of its members.
+func (ds *DataStore) SDiffStore(destination string, keys ...string) (int, error) {
+	return ds.combineStore(setDifference, destination, keys)
+}
+
+func (ds *DataStore) combineMembers(operation setOperation, keys []string) ([]string, error) {
+	defer ds.lockShards(keys, false)()
+	result, err := ds.combine(operation, keys)
+	if err != nil {
+		return nil, err
+	}
+	return result.Members(), nil
+}
+
+// combineStore replaces destination item of any type by the result without TTL, empty result removes destination.
+// Shards of destination and source keys are locked together, so the result is stored atomically.
+func (ds *DataStore) combineStore(operation setOperation, destination string, keys []string) (int, error) {
+	defer ds.lockShards(append([]string{destination}, keys...), true)()
+	result, err := ds.combine(operation, keys)
+	if err != nil {
+		return 0, err
+	}
+	ds.shardFor(destination).updateSet(destination, datatype.DataType{}, result)
+	return len(result), nil
+}
+
+// combine applies operation to the sets stored under the keys, absent sets are treated as empty ones.
+// Should be called under the lock of shards of the keys.
+func (ds *DataStore) combine(operation setOperation, keys []string) (datatype.Set, error) {
+	sets := make([]datatype.Set, len(keys))
+	for i, key := range keys {
+		_, set, err := ds.shardFor(key).members(key)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	result := make(datatype.Set)
+	if len(sets) == 0 {
+		return result, nil
+	}
+	switch operation {
+	case setUnion:
+		for _, set := range sets {
+			for member := range set {
+				result[member] = true
+			}
+		}
+	case setIntersection:
+		smallest := 0
+		for i, set := range sets {
+			if len(set) < len(sets[smallest]) {
+				smallest = i
+			}
+		}
+		for member := range sets[smallest] {
+			if containedByAll(sets, member) {
+				result[member] = true
+			}
+		}
+	case setDifference:
+		for member := range sets[0] {
+			if !containedByAny(sets[1:], member) {
+				result[member] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// members returns item with the set stored under the key. Set is nil when there is no such item.
+func (s *shard) members(key string) (datatype.DataType, datatype.Set, error) {
+	item, ok := s.lookup(key)
+	if !ok || item.IsExpired(time.Now()) {
+		return datatype.DataType{}, nil, nil
+	}
+	set, ok := item.Value.(datatype.Set)
+	if !ok {
+		return item, nil, ErrWrongType
+	}
+	return item, set, nil
+}
+
+// updateSet saves new set keeping TTL of the item, empty set is removed.
+func (s *shard) updateSet(key string, item datatype.DataType, set datatype.Set) {
+	if len(set) == 0 {
+		s.delete(key)
+		return
+	}
+	item.Value = set
+	s.set(key, item)
+}
+
+func copySet(set datatype.Set, extra int) datatype.Set {
+	result := make(datatype.Set, len(set)+extra)
+	for member := range set {
+		result[member] = true
+	}
+	return result
+}
+
+func containedByAll(sets []datatype.Set, member string) bool {
+	for _, set := range sets {
+		if !set.Contains(member) {
+			return false
+		}
+	}
+	return true
+}
+
+func containedByAny(sets []datatype.Set, member string) bool {
+	for _, set := range sets {
+		if set.Contains(member) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDataStore_SAdd_SRem(t *testing.T) {
+	dataStore := NewDataStore()
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+
+	added, err := dataStore.SAdd("flags", "dark-mode", "beta", "dark-mode")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, added)
+	added, _ = dataStore.SAdd("flags", "beta")
+	assert.Equal(t, 0, added)
+	assert.Equal(t, 1, len(operations), "Set is not saved when nothing is added")
+	ttl, _ := dataStore.Ttl("flags")
+	assert.Equal(t, NoTtl, ttl, "Set created by add has no TTL")
+
+	removed, err := dataStore.SRem("flags", "beta", "alpha")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, removed)
+	members, _ := dataStore.SMembers("flags")
+	assert.Equal(t, []string{"dark-mode"}, members)
+	assert.Equal(t, datatype.NewSetOf("dark-mode", "beta"), operations[0].Value.Value, "Sets are copy-on-write")
+
+	dataStore.SRem("flags", "dark-mode")
+	assert.Equal(t, false, dataStore.Contains("flags"), "Empty set is removed")
+	removed, _ = dataStore.SRem("flags", "dark-mode")
+	assert.Equal(t, 0, removed)
+}
+
+func TestDataStore_SIsMember_SCard(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("flags", datatype.NewSet([]string{"dark-mode", "beta"}, time.Minute))
+
+	isMember, err := dataStore.SIsMember("flags", "beta")
+	assert.Nil(t, err)
+	assert.Equal(t, true, isMember)
+	isMember, _ = dataStore.SIsMember("flags", "alpha")
+	assert.Equal(t, false, isMember)
+	isMember, _ = dataStore.SIsMember("absent", "beta")
+	assert.Equal(t, false, isMember)
+
+	count, _ := dataStore.SCard("flags")
+	assert.Equal(t, 2, count)
+	count, _ = dataStore.SCard("absent")
+	assert.Equal(t, 0, count)
+	members, _ := dataStore.SMembers("absent")
+	assert.Equal(t, []string{}, members)
+}
+
+func TestDataStore_sets_wrongType(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("flags", datatype.NewSet([]string{"beta"}, time.Minute))
+
+	_, err := dataStore.SAdd("name", "beta")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.SMembers("name")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.SInter("flags", "name")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.LLen("flags")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.IncrBy("flags", 1, 0)
+	assert.Equal(t, ErrWrongType, err)
+}
+
+func TestDataStore_SUnion_SInter_SDiff(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.SAdd("ivan", "dark-mode", "beta", "new-search")
+	dataStore.SAdd("petr", "beta", "new-search", "export")
+	dataStore.SAdd("anna", "beta")
+
+	members, err := dataStore.SUnion("ivan", "petr", "absent")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"beta", "dark-mode", "export", "new-search"}, members)
+	members, _ = dataStore.SInter("ivan", "petr", "anna")
+	assert.Equal(t, []string{"beta"}, members)
+	members, _ = dataStore.SInter("ivan", "absent")
+	assert.Equal(t, []string{}, members)
+	members, _ = dataStore.SDiff("ivan", "petr")
+	assert.Equal(t, []string{"dark-mode"}, members)
+	members, _ = dataStore.SDiff("absent", "petr")
+	assert.Equal(t, []string{}, members)
+}
+
+func TestDataStore_SUnionStore_SInterStore_SDiffStore(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.SAdd("ivan", "dark-mode", "beta")
+	dataStore.SAdd("petr", "beta", "export")
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	count, err := dataStore.SUnionStore("all", "ivan", "petr")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+	members, _ := dataStore.SMembers("all")
+	assert.Equal(t, []string{"beta", "dark-mode", "export"}, members)
+
+	count, _ = dataStore.SInterStore("name", "ivan", "petr")
+	assert.Equal(t, 1, count)
+	members, _ = dataStore.SMembers("name")
+	assert.Equal(t, []string{"beta"}, members, "Destination of any type is replaced")
+
+	count, _ = dataStore.SDiffStore("ivan", "ivan", "petr")
+	assert.Equal(t, 1, count)
+	members, _ = dataStore.SMembers("ivan")
+	assert.Equal(t, []string{"dark-mode"}, members, "Destination could be one of the sources")
+
+	count, _ = dataStore.SInterStore("all", "ivan", "petr")
+	assert.Equal(t, 0, count)
+	assert.Equal(t, false, dataStore.Contains("all"), "Empty result removes destination")
+}
+
+func ExampleDataStore_SInter() {
+	dataStore := NewDataStore()
+	dataStore.SAdd("ivan", "dark-mode", "beta")
+	dataStore.SAdd("petr", "beta", "export")
+	members, _ := dataStore.SInter("ivan", "petr")
+	fmt.Println(members)
+	// Output: [beta]
+}
+
+func BenchmarkDataStore_SInter(b *testing.B) {
+	dataStore := NewDataStore()
+	for i := 0; i < 1000; i++ {
+		dataStore.SAdd("even", fmt.Sprint(2*i))
+		dataStore.SAdd("triple", fmt.Sprint(3*i))
+	}
+
+	for n := 0; n < b.N; n++ {
+		dataStore.SInter("even", "triple")
+	}
+}
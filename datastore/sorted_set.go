@@ -0,0 +1,202 @@
+package datastore
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"math"
+	"time"
+)
+
+// Sorted set operations work with items holding *datatype.SortedSet value. Scores are finite numbers,
+// so sorted sets could be always encoded into json.
+// Sorted set is created without TTL when members are added to absent key and removed when its last member is removed.
+// Unlike other collections sorted set is changed in place, listeners receive the same *datatype.SortedSet each time.
+
+// ZAdd sets scores of the members and returns amount of added members.
+// Returns ErrNotFloat if any score is not a finite number.
+func (ds *DataStore) ZAdd(key string, members map[string]float64) (int, error) {
+	for _, score := range members {
+		if !isFinite(score) {
+			return 0, ErrNotFloat
+		}
+	}
+
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	added := 0
+	err := s.changeSortedSet(key, true, func(set *datatype.SortedSet) bool {
+		changed := false
+		for member, score := range members {
+			if current, ok := set.Score(member); ok && current == score {
+				continue
+			}
+			if set.Add(member, score) {
+				added++
+			}
+			changed = true
+		}
+		return changed
+	})
+	return added, err
+}
+
+// ZIncrBy increments score of the member by delta and returns new score. Absent member is treated as zero.
+// Returns ErrNotFloat if the result is not a finite number.
+func (ds *DataStore) ZIncrBy(key, member string, delta float64) (float64, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	var score float64
+	err := s.changeSortedSet(key, true, func(set *datatype.SortedSet) bool {
+		current, _ := set.Score(member)
+		score = current + delta
+		if !isFinite(score) {
+			return false
+		}
+		set.Add(member, score)
+		return true
+	})
+	if err == nil && !isFinite(score) {
+		return 0, ErrNotFloat
+	}
+	return score, err
+}
+
+// ZRem removes the members and returns amount of removed members.
+func (ds *DataStore) ZRem(key string, members ...string) (int, error) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	removed := 0
+	err := s.changeSortedSet(key, false, func(set *datatype.SortedSet) bool {
+		for _, member := range members {
+			if set.Remove(member) {
+				removed++
+			}
+		}
+		return removed > 0
+	})
+	return removed, err
+}
+
+// ZScore returns score of the member. Returns false if there is no such member.
+func (ds *DataStore) ZScore(key, member string) (float64, bool, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.sortedSet(key)
+	if err != nil || set == nil {
+		return 0, false, err
+	}
+	score, ok := set.Score(member)
+	return score, ok, nil
+}
+
+// ZCard returns amount of members of the sorted set, zero for absent sorted set.
+func (ds *DataStore) ZCard(key string) (int, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.sortedSet(key)
+	if err != nil || set == nil {
+		return 0, err
+	}
+	return set.Len(), nil
+}
+
+// ZRank returns position of the member ordered by score ascending (descending if reversed is true), starting from 0.
+// Returns false if there is no such member.
+func (ds *DataStore) ZRank(key, member string, reversed bool) (int, bool, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.sortedSet(key)
+	if err != nil || set == nil {
+		return 0, false, err
+	}
+	rank, ok := set.Rank(member, reversed)
+	return rank, ok, nil
+}
+
+// ZRange returns members between start and stop positions inclusive ordered by score ascending
+// (descending if reversed is true). Negative positions are counted from the end.
+func (ds *DataStore) ZRange(key string, start, stop int, reversed bool) ([]datatype.ScoredMember, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.sortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return []datatype.ScoredMember{}, nil
+	}
+	from, to := normalizeRange(start, stop, set.Len())
+	return set.Range(from, to, reversed), nil
+}
+
+// ZRangeByScore returns members with scores within the range ordered by score ascending (descending if reversed is true).
+// First offset members are skipped, negative count means that all remaining members are returned.
+func (ds *DataStore) ZRangeByScore(key string, scoreRange datatype.ScoreRange, reversed bool, offset, count int) ([]datatype.ScoredMember, error) {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, set, err := s.sortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return []datatype.ScoredMember{}, nil
+	}
+	return set.RangeByScore(scoreRange, reversed, offset, count), nil
+}
+
+// sortedSet returns item with the sorted set stored under the key. Sorted set is nil when there is no such item.
+func (s *shard) sortedSet(key string) (datatype.DataType, *datatype.SortedSet, error) {
+	item, ok := s.lookup(key)
+	if !ok || item.IsExpired(time.Now()) {
+		return datatype.DataType{}, nil, nil
+	}
+	set, ok := item.Value.(*datatype.SortedSet)
+	if !ok {
+		return item, nil, ErrWrongType
+	}
+	return item, set, nil
+}
+
+// changeSortedSet applies change to the sorted set stored under the key. When there is no such sorted set
+// new one without TTL is created if create is true, otherwise change is not applied.
+// Change returns flag was the sorted set changed, changed sorted set is saved and empty one is removed.
+func (s *shard) changeSortedSet(key string, create bool, change func(set *datatype.SortedSet) bool) error {
+	item, set, err := s.sortedSet(key)
+	if err != nil {
+		return err
+	}
+	isStored := set != nil
+	if !isStored {
+		if !create {
+			return nil
+		}
+		item = datatype.NewSortedSet(nil, 0)
+		set = item.Value.(*datatype.SortedSet)
+	}
+
+	sizeBefore := s.entrySize(key, item)
+	if !change(set) {
+		return nil
+	}
+	if isStored {
+		// sorted set is changed in place, so stored item already has new size
+		s.memory += s.entrySize(key, item) - sizeBefore
+	}
+	if set.Len() == 0 {
+		s.delete(key)
+		return nil
+	}
+	s.set(key, item)
+	return nil
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
@@ -0,0 +1,169 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataStore_ZAdd_ZRem(t *testing.T) {
+	dataStore := NewDataStore()
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+
+	added, err := dataStore.ZAdd("leaders", map[string]float64{"ivan": 150, "petr": 90})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, added)
+	added, _ = dataStore.ZAdd("leaders", map[string]float64{"ivan": 150})
+	assert.Equal(t, 0, added)
+	assert.Equal(t, 1, len(operations), "Sorted set is not saved when nothing is changed")
+	added, _ = dataStore.ZAdd("leaders", map[string]float64{"ivan": 200, "anna": 120})
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 2, len(operations))
+	ttl, _ := dataStore.Ttl("leaders")
+	assert.Equal(t, NoTtl, ttl, "Sorted set created by add has no TTL")
+
+	removed, err := dataStore.ZRem("leaders", "petr", "olga")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, removed)
+	count, _ := dataStore.ZCard("leaders")
+	assert.Equal(t, 2, count)
+	dataStore.ZRem("leaders", "ivan", "anna")
+	assert.Equal(t, false, dataStore.Contains("leaders"), "Empty sorted set is removed")
+	removed, _ = dataStore.ZRem("leaders", "ivan")
+	assert.Equal(t, 0, removed)
+
+	_, err = dataStore.ZAdd("leaders", map[string]float64{"ivan": math.NaN()})
+	assert.Equal(t, ErrNotFloat, err)
+	_, err = dataStore.ZAdd("leaders", map[string]float64{"ivan": math.Inf(1)})
+	assert.Equal(t, ErrNotFloat, err)
+}
+
+func TestDataStore_ZIncrBy_ZScore(t *testing.T) {
+	dataStore := NewDataStore()
+
+	score, err := dataStore.ZIncrBy("leaders", "ivan", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, score)
+	score, _ = dataStore.ZIncrBy("leaders", "ivan", 5.5)
+	assert.Equal(t, 15.5, score)
+	score, ok, err := dataStore.ZScore("leaders", "ivan")
+	assert.Nil(t, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 15.5, score)
+	_, ok, _ = dataStore.ZScore("leaders", "petr")
+	assert.Equal(t, false, ok)
+	_, ok, _ = dataStore.ZScore("absent", "ivan")
+	assert.Equal(t, false, ok)
+
+	_, err = dataStore.ZIncrBy("leaders", "ivan", math.Inf(1))
+	assert.Equal(t, ErrNotFloat, err)
+	score, _, _ = dataStore.ZScore("leaders", "ivan")
+	assert.Equal(t, 15.5, score, "Score is not changed")
+}
+
+func TestDataStore_ZIncrBy_concurrent(t *testing.T) {
+	dataStore := NewDataStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dataStore.ZIncrBy("leaders", "ivan", 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	score, _, _ := dataStore.ZScore("leaders", "ivan")
+	assert.Equal(t, 1000.0, score)
+}
+
+func TestDataStore_ZRank_ZRange(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.ZAdd("leaders", map[string]float64{"ivan": 150, "petr": 90, "anna": 150, "olga": 300})
+
+	rank, ok, err := dataStore.ZRank("leaders", "ivan", false)
+	assert.Nil(t, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 2, rank)
+	rank, _, _ = dataStore.ZRank("leaders", "olga", true)
+	assert.Equal(t, 0, rank)
+	_, ok, _ = dataStore.ZRank("leaders", "oleg", false)
+	assert.Equal(t, false, ok)
+
+	members, err := dataStore.ZRange("leaders", 0, 1, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []datatype.ScoredMember{{Member: "olga", Score: 300}, {Member: "ivan", Score: 150}}, members)
+	members, _ = dataStore.ZRange("leaders", -2, -1, false)
+	assert.Equal(t, []datatype.ScoredMember{{Member: "ivan", Score: 150}, {Member: "olga", Score: 300}}, members)
+	members, _ = dataStore.ZRange("absent", 0, -1, false)
+	assert.Equal(t, []datatype.ScoredMember{}, members)
+}
+
+func TestDataStore_ZRangeByScore(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.ZAdd("tasks", map[string]float64{"backup": 100, "report": 200, "cleanup": 300})
+
+	members, err := dataStore.ZRangeByScore("tasks", datatype.ScoreRange{Min: math.Inf(-1), Max: 200}, false, 0, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []datatype.ScoredMember{{Member: "backup", Score: 100}, {Member: "report", Score: 200}}, members)
+	members, _ = dataStore.ZRangeByScore("tasks", datatype.ScoreRange{Min: 100, Max: 300, MinExclusive: true}, true, 0, 1)
+	assert.Equal(t, []datatype.ScoredMember{{Member: "cleanup", Score: 300}}, members)
+	members, _ = dataStore.ZRangeByScore("absent", datatype.ScoreRange{Min: 0, Max: 1}, false, 0, -1)
+	assert.Equal(t, []datatype.ScoredMember{}, members)
+}
+
+func TestDataStore_sortedSets_wrongType(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.ZAdd("leaders", map[string]float64{"ivan": 150})
+
+	_, err := dataStore.ZAdd("name", map[string]float64{"ivan": 150})
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.ZRange("name", 0, -1, false)
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.SMembers("leaders")
+	assert.Equal(t, ErrWrongType, err)
+	_, err = dataStore.IncrBy("leaders", 1, 0)
+	assert.Equal(t, ErrWrongType, err)
+}
+
+func TestDataStore_ZAdd_memoryAccounting(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.ZAdd("leaders", map[string]float64{"ivan": 150})
+	memory := dataStore.Stats().Memory
+
+	dataStore.ZAdd("leaders", map[string]float64{"petr": 90})
+	assert.Equal(t, true, dataStore.Stats().Memory > memory)
+	dataStore.ZRem("leaders", "petr")
+	assert.Equal(t, memory, dataStore.Stats().Memory)
+	dataStore.ZRem("leaders", "ivan")
+	assert.Equal(t, int64(0), dataStore.Stats().Memory)
+}
+
+func ExampleDataStore_ZRange() {
+	dataStore := NewDataStore()
+	dataStore.ZAdd("leaders", map[string]float64{"ivan": 150, "petr": 90, "olga": 300})
+	top, _ := dataStore.ZRange("leaders", 0, 1, true)
+	fmt.Println(top)
+	// Output: [{olga 300} {ivan 150}]
+}
+
+func BenchmarkDataStore_ZIncrBy(b *testing.B) {
+	dataStore := NewDataStore()
+	for i := 0; i < 1000; i++ {
+		dataStore.ZAdd("leaders", map[string]float64{fmt.Sprint(i): float64(i)})
+	}
+
+	for n := 0; n < b.N; n++ {
+		dataStore.ZIncrBy("leaders", fmt.Sprint(n%1000), 1)
+	}
+}
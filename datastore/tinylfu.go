@@ -0,0 +1,248 @@
+package datastore
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// Segments of W-TinyLFU policy. Rejected segment contains keys which lost admission contest, they are evicted first.
+const (
+	segmentWindow = iota
+	segmentProbation
+	segmentProtected
+	segmentRejected
+)
+
+// Shares of capacity taken by the admission window and by the protected segment of the main area.
+const (
+	windowPercent    = 1
+	protectedPercent = 80
+)
+
+// sketchDepth is the amount of rows of count-min sketch.
+const sketchDepth = 4
+
+// sketchMaxCount is the maximal value of counter, counters are 4 bits wide in the original design.
+const sketchMaxCount = 15
+
+// sketchWidthFactor is the amount of counters per row for each item of capacity, it keeps collisions rare.
+const sketchWidthFactor = 8
+
+// sketch is a count-min sketch which estimates access frequency of keys.
+// Counters are halved after each sampleSize increments, so stale popularity decays.
+type sketch struct {
+	rows       [sketchDepth][]uint8
+	mask       uint32
+	additions  int
+	sampleSize int
+}
+
+func newSketch(capacity int) *sketch {
+	width := 64
+	for width < sketchWidthFactor*capacity {
+		width *= 2
+	}
+	s := &sketch{mask: uint32(width - 1), sampleSize: 10 * capacity}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// indexes returns position of the key counter in each row using double hashing.
+func (s *sketch) indexes(key string) [sketchDepth]uint32 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	sum := hash.Sum64()
+	low, high := uint32(sum), uint32(sum>>32)
+	var result [sketchDepth]uint32
+	for i := range result {
+		result[i] = (low + uint32(i)*high) & s.mask
+	}
+	return result
+}
+
+func (s *sketch) increment(key string) {
+	for i, index := range s.indexes(key) {
+		if s.rows[i][index] < sketchMaxCount {
+			s.rows[i][index]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.age()
+	}
+}
+
+func (s *sketch) estimate(key string) uint8 {
+	result := uint8(sketchMaxCount)
+	for i, index := range s.indexes(key) {
+		if s.rows[i][index] < result {
+			result = s.rows[i][index]
+		}
+	}
+	return result
+}
+
+// age halves all counters.
+func (s *sketch) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] /= 2
+		}
+	}
+	s.additions /= 2
+}
+
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] = 0
+		}
+	}
+	s.additions = 0
+}
+
+// tinyLfuEntry points to the key inside one of segments.
+type tinyLfuEntry struct {
+	segment int
+	element *list.Element
+}
+
+// tinyLfu implements W-TinyLFU policy: new keys get into small LRU admission window,
+// keys leaving the window get into segmented LRU main area.
+// When the main area is full, the key leaving the window competes with the main area victim by estimated frequency
+// and the loser is rejected, so one-time accesses can't flush out popular keys.
+type tinyLfu struct {
+	mutex             sync.Mutex
+	sketch            *sketch
+	segments          [4]*list.List
+	entries           map[string]*tinyLfuEntry
+	windowCapacity    int
+	mainCapacity      int
+	protectedCapacity int
+}
+
+// NewTinyLfuPolicy creates W-TinyLFU policy for collection of approximately capacity items.
+func NewTinyLfuPolicy(capacity int) EvictionPolicy {
+	return newTinyLfu(capacity)
+}
+
+func newTinyLfu(capacity int) *tinyLfu {
+	if capacity < 1 {
+		capacity = 1
+	}
+	t := &tinyLfu{
+		sketch:            newSketch(capacity),
+		entries:           make(map[string]*tinyLfuEntry),
+		windowCapacity:    capacity * windowPercent / 100,
+		protectedCapacity: capacity * protectedPercent / 100,
+	}
+	if t.windowCapacity < 1 {
+		t.windowCapacity = 1
+	}
+	t.mainCapacity = capacity - t.windowCapacity
+	for i := range t.segments {
+		t.segments[i] = list.New()
+	}
+	return t
+}
+
+// Add puts new key into admission window, update of existing key counts as its access.
+func (t *tinyLfu) Add(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sketch.increment(key)
+	if entry, ok := t.entries[key]; ok {
+		t.access(entry)
+		return
+	}
+	t.entries[key] = &tinyLfuEntry{segmentWindow, t.segments[segmentWindow].PushFront(key)}
+	for t.segments[segmentWindow].Len() > t.windowCapacity {
+		t.admit(t.segments[segmentWindow].Back().Value.(string))
+	}
+}
+
+// admit moves candidate leaving the window into the main area. When the main area is full, candidate competes
+// with the main area victim by estimated frequency and the loser is rejected.
+// Candidate is the oldest key of the window, so it is never the key being added.
+func (t *tinyLfu) admit(candidate string) {
+	if t.segments[segmentProbation].Len()+t.segments[segmentProtected].Len() < t.mainCapacity {
+		t.move(t.entries[candidate], segmentProbation)
+		return
+	}
+	victim := t.segments[segmentProbation].Back()
+	if victim == nil {
+		victim = t.segments[segmentProtected].Back()
+	}
+	if victim == nil || t.sketch.estimate(candidate) <= t.sketch.estimate(victim.Value.(string)) {
+		t.move(t.entries[candidate], segmentRejected)
+		return
+	}
+	t.move(t.entries[victim.Value.(string)], segmentRejected)
+	t.move(t.entries[candidate], segmentProbation)
+}
+
+// Touch records access of the key.
+func (t *tinyLfu) Touch(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sketch.increment(key)
+	if entry, ok := t.entries[key]; ok {
+		t.access(entry)
+	}
+}
+
+// access promotes key from probation to protected segment or moves it to the front of its segment.
+func (t *tinyLfu) access(entry *tinyLfuEntry) {
+	if entry.segment != segmentProbation {
+		t.segments[entry.segment].MoveToFront(entry.element)
+		return
+	}
+	t.move(entry, segmentProtected)
+	for t.segments[segmentProtected].Len() > t.protectedCapacity {
+		t.move(t.entries[t.segments[segmentProtected].Back().Value.(string)], segmentProbation)
+	}
+}
+
+// move puts key to the front of another segment.
+func (t *tinyLfu) move(entry *tinyLfuEntry, segment int) {
+	key := t.segments[entry.segment].Remove(entry.element)
+	entry.segment = segment
+	entry.element = t.segments[segment].PushFront(key)
+}
+
+// Remove stops tracking of the key. Its frequency is kept by the sketch.
+func (t *tinyLfu) Remove(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if entry, ok := t.entries[key]; ok {
+		t.segments[entry.segment].Remove(entry.element)
+		delete(t.entries, key)
+	}
+}
+
+// Victim returns key rejected by admission contest. When there is no such key, the collection is limited
+// by other than amount of items, so keys are evicted from the main area first and from the window at last.
+func (t *tinyLfu) Victim() (string, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, segment := range []int{segmentRejected, segmentProbation, segmentProtected, segmentWindow} {
+		if victim := t.segments[segment].Back(); victim != nil {
+			return victim.Value.(string), true
+		}
+	}
+	return "", false
+}
+
+// Reset stops tracking of all keys and forgets their frequencies.
+func (t *tinyLfu) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, segment := range t.segments {
+		segment.Init()
+	}
+	t.entries = make(map[string]*tinyLfuEntry)
+	t.sketch.reset()
+}
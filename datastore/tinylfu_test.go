@@ -0,0 +1,96 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSketch(t *testing.T) {
+	s := newSketch(100)
+	for i := 0; i < 5; i++ {
+		s.increment("key 1")
+	}
+	s.increment("key 2")
+	assert.Equal(t, uint8(5), s.estimate("key 1"))
+	assert.Equal(t, uint8(1), s.estimate("key 2"))
+	assert.Equal(t, uint8(0), s.estimate("key 3"))
+
+	for i := 0; i < 20; i++ {
+		s.increment("key 1")
+	}
+	assert.Equal(t, uint8(sketchMaxCount), s.estimate("key 1"), "Counters are saturated")
+
+	s.age()
+	assert.Equal(t, uint8(sketchMaxCount/2), s.estimate("key 1"))
+	assert.Equal(t, uint8(0), s.estimate("key 2"))
+
+	s.reset()
+	assert.Equal(t, uint8(0), s.estimate("key 1"))
+}
+
+func TestTinyLfu(t *testing.T) {
+	l := newTinyLfu(2)
+	_, ok := l.Victim()
+	assert.Equal(t, false, ok, "Empty policy has no victim")
+
+	l.Add("popular")
+	victim, _ := l.Victim()
+	assert.Equal(t, "popular", victim, "Key from window is evicted when main area is empty")
+
+	for i := 0; i < 5; i++ {
+		l.Touch("popular")
+	}
+	l.Add("one-time")
+	assert.Equal(t, segmentProbation, l.entries["popular"].segment, "Key leaving window is admitted while main area has room")
+	l.Add("new")
+	victim, _ = l.Victim()
+	assert.Equal(t, "one-time", victim, "Rare key is not admitted into full main area")
+	assert.Equal(t, segmentWindow, l.entries["new"].segment, "Added key never competes during its own insert")
+
+	l.Remove("one-time")
+	for i := 0; i < 10; i++ {
+		l.Touch("new")
+	}
+	l.Add("other")
+	victim, _ = l.Victim()
+	assert.Equal(t, "popular", victim, "Frequent key is admitted into main area")
+	assert.Equal(t, segmentProbation, l.entries["new"].segment)
+
+	l.Reset()
+	_, ok = l.Victim()
+	assert.Equal(t, false, ok, "Reset policy has no victim")
+}
+
+func TestTinyLfu_protectedSegment(t *testing.T) {
+	l := newTinyLfu(10)
+	for i := 1; i <= 5; i++ {
+		l.Add(fmt.Sprintf("key %d", i))
+	}
+	l.Touch("key 1")
+	assert.Equal(t, segmentProtected, l.entries["key 1"].segment, "Accessed key is promoted")
+	assert.Equal(t, segmentProbation, l.entries["key 2"].segment)
+	assert.Equal(t, segmentWindow, l.entries["key 5"].segment)
+
+	for i := 2; i <= 4; i++ {
+		l.Touch(fmt.Sprintf("key %d", i))
+	}
+	assert.Equal(t, 4, l.segments[segmentProtected].Len())
+	for i := 6; i <= 15; i++ {
+		l.Add(fmt.Sprintf("key %d", i))
+	}
+	for i := 5; i <= 15; i++ {
+		l.Touch(fmt.Sprintf("key %d", i))
+	}
+	assert.Equal(t, l.protectedCapacity, l.segments[segmentProtected].Len(), "Protected segment is bounded")
+}
+
+func BenchmarkTinyLfu_Touch(b *testing.B) {
+	l := newTinyLfu(100)
+	l.Add("key 1")
+	l.Add("key 2")
+
+	for n := 0; n < b.N; n++ {
+		l.Touch("key 1")
+	}
+}
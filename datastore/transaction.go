@@ -0,0 +1,49 @@
+package datastore
+
+import "time"
+
+// noLock is a rwLocker of transaction shards, DataStore the transaction is started from is locked during whole transaction.
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+// Transaction executes fn under the lock of the collection, so all operations applied to tx
+// are atomic for other clients. Tx shares items with the collection and must not be used after fn returns.
+// Background expirer must not be started or stopped from fn.
+//
+// Watch contains versions of items read by caller before the transaction, zero version means absent item.
+// Transaction is aborted with ErrWatchedKeyChanged if any of them was changed since then.
+// Operations already applied by fn are not rolled back when fn returns error.
+func (ds *DataStore) Transaction(watch map[string]uint64, fn func(tx *DataStore) error) error {
+	ds.Lock()
+	defer ds.Unlock()
+	for key, version := range watch {
+		if ds.shardFor(key).versionOf(key) != version {
+			return ErrWatchedKeyChanged
+		}
+	}
+	tx := &DataStore{shards: make([]*shard, len(ds.shards)), hub: ds.hub}
+	for i, s := range ds.shards {
+		tx.shards[i] = &shard{rwLocker: noLock{}, state: s.state}
+	}
+	return fn(tx)
+}
+
+// Version returns version of the item, zero version means absent item.
+func (ds *DataStore) Version(key string) uint64 {
+	s := ds.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	return s.versionOf(key)
+}
+
+func (s *shard) versionOf(key string) uint64 {
+	item, ok := s.lookup(key)
+	if !ok || item.IsExpired(time.Now()) {
+		return 0
+	}
+	return item.Version
+}
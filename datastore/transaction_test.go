@@ -0,0 +1,135 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataStore_Transaction(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.RPush("todo", "buy milk", "call Ivan")
+
+	err := dataStore.Transaction(nil, func(tx *DataStore) error {
+		task, _, err := tx.LPop("todo")
+		if err != nil {
+			return err
+		}
+		if _, err := tx.RPush("done", task); err != nil {
+			return err
+		}
+		_, err = tx.IncrBy("done:count", 1, 0)
+		return err
+	})
+
+	assert.Nil(t, err)
+	todo, _ := dataStore.LRange("todo", 0, -1)
+	assert.Equal(t, []interface{}{"call Ivan"}, todo)
+	done, _ := dataStore.LRange("done", 0, -1)
+	assert.Equal(t, []interface{}{"buy milk"}, done)
+	count, _ := dataStore.Get("done:count")
+	assert.Equal(t, int64(1), count.(datatype.DataType).Value)
+}
+
+func TestDataStore_Transaction_error(t *testing.T) {
+	dataStore := NewDataStore()
+	expected := errors.New("stop")
+
+	err := dataStore.Transaction(nil, func(tx *DataStore) error {
+		tx.Set("name", datatype.NewString("Ivan", 0))
+		return expected
+	})
+
+	assert.Equal(t, expected, err)
+	assert.Equal(t, true, dataStore.Contains("name"), "Applied operations are not rolled back")
+}
+
+func TestDataStore_Transaction_watch(t *testing.T) {
+	dataStore := NewDataStore()
+	version := dataStore.Set("balance", datatype.DataType{Value: int64(100)}).Version
+	assert.Equal(t, version, dataStore.Version("balance"))
+	assert.Equal(t, uint64(0), dataStore.Version("limit"))
+
+	watch := map[string]uint64{"balance": version, "limit": 0}
+	dataStore.IncrBy("balance", -30, 0)
+	called := false
+	err := dataStore.Transaction(watch, func(tx *DataStore) error {
+		called = true
+		return nil
+	})
+	assert.Equal(t, ErrWatchedKeyChanged, err)
+	assert.Equal(t, false, called, "Aborted transaction is not executed")
+
+	watch["balance"] = dataStore.Version("balance")
+	err = dataStore.Transaction(watch, func(tx *DataStore) error {
+		_, err := tx.IncrBy("balance", -50, 0)
+		return err
+	})
+	assert.Nil(t, err)
+
+	dataStore.Set("limit", datatype.DataType{Value: int64(10)})
+	err = dataStore.Transaction(map[string]uint64{"limit": 0}, func(tx *DataStore) error { return nil })
+	assert.Equal(t, ErrWatchedKeyChanged, err, "Created item is a change of absent one")
+
+	dataStore.Set("session", datatype.NewString("token", -time.Second))
+	err = dataStore.Transaction(map[string]uint64{"session": 0}, func(tx *DataStore) error { return nil })
+	assert.Nil(t, err, "Expired item is treated as absent")
+}
+
+func TestDataStore_Transaction_isolated(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("a", datatype.DataType{Value: int64(1000)})
+	dataStore.Set("b", datatype.DataType{Value: int64(0)})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dataStore.Transaction(nil, func(tx *DataStore) error {
+					tx.IncrBy("a", -1, 0)
+					tx.IncrBy("b", 1, 0)
+					return nil
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dataStore.Transaction(nil, func(tx *DataStore) error {
+					a, _ := tx.Get("a")
+					b, _ := tx.Get("b")
+					assert.Equal(t, int64(1000), a.(datatype.DataType).Value.(int64)+b.(datatype.DataType).Value.(int64))
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _ := dataStore.Get("b")
+	assert.Equal(t, int64(1000), value.(datatype.DataType).Value)
+}
+
+func ExampleDataStore_Transaction() {
+	dataStore := NewDataStore()
+	dataStore.RPush("queue", "job-1", "job-2")
+	watch := map[string]uint64{"queue": dataStore.Version("queue")}
+
+	err := dataStore.Transaction(watch, func(tx *DataStore) error {
+		job, _, err := tx.LPop("queue")
+		if err != nil {
+			return err
+		}
+		_, err = tx.RPush("processing", job)
+		return err
+	})
+	processing, _ := dataStore.LRange("processing", 0, -1)
+	fmt.Println(err, processing)
+	// Output:
+	// <nil> [job-1]
+}
@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"time"
+)
+
+// NoTtl is returned by Ttl for items which never expire.
+const NoTtl time.Duration = -1
+
+// Ttl returns remaining time to live of the item or NoTtl if the item never expires.
+// Returns false if there is no such item.
+func (ds *DataStore) Ttl(key string) (time.Duration, bool) {
+	s := ds.shardFor(key)
+	s.RLock()
+	ttl, ok := s.ttl(key)
+	s.RUnlock()
+	if !ok {
+		s.expireKey(key)
+	}
+	return ttl, ok
+}
+
+func (s *shard) ttl(key string) (time.Duration, bool) {
+	value, ok := s.lookup(key)
+	now := time.Now()
+	if !ok || value.IsExpired(now) {
+		return 0, false
+	}
+	if value.IsPersistent() {
+		return NoTtl, true
+	}
+	return value.DeathTime.Sub(now), true
+}
+
+// Expire sets new TTL of the item and returns updated item. Not positive TTL removes the item.
+// Returns false if there is no such item.
+func (ds *DataStore) Expire(key string, ttl time.Duration) (datatype.DataType, bool) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	return s.expireAt(key, time.Now().Add(ttl))
+}
+
+// ExpireAt sets new DeathTime of the item and returns updated item. DeathTime in the past removes the item.
+// Returns false if there is no such item.
+func (ds *DataStore) ExpireAt(key string, deathTime time.Time) (datatype.DataType, bool) {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	return s.expireAt(key, deathTime)
+}
+
+// expireAt replaces the item by its copy with new DeathTime, so its position in DeathTime ordering is updated.
+func (s *shard) expireAt(key string, deathTime time.Time) (datatype.DataType, bool) {
+	value, ok := s.lookup(key)
+	now := time.Now()
+	if !ok || value.IsExpired(now) {
+		return datatype.DataType{}, false
+	}
+	value = datatype.DataType{Value: value.Value, Ttl: deathTime.Sub(now), DeathTime: deathTime, Sliding: value.Sliding}
+	if !deathTime.After(now) {
+		s.delete(key)
+		return value, true
+	}
+	return s.set(key, value), true
+}
+
+// SlideTo pushes DeathTime of sliding item forward to deathTime keeping its Ttl and version, as its read would do.
+// It is used to restore sliding expiration, see OperationSlide. Returns false if there is no such sliding item
+// or its DeathTime is not before deathTime.
+func (ds *DataStore) SlideTo(key string, deathTime time.Time) bool {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	value, ok := s.lookup(key)
+	if !ok || !value.Sliding || value.IsPersistent() || value.IsExpired(time.Now()) || !value.DeathTime.Before(deathTime) {
+		return false
+	}
+	value.DeathTime = deathTime
+	s.cache.Replace(key, value)
+	s.notify(Operation{Type: OperationSlide, Key: key, Value: value})
+	return true
+}
+
+// Persist removes TTL of the item, so it never expires.
+// Returns false if there is no such item or it has no TTL already.
+func (ds *DataStore) Persist(key string) bool {
+	s := ds.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+	return s.persist(key)
+}
+
+func (s *shard) persist(key string) bool {
+	value, ok := s.lookup(key)
+	if !ok || value.IsPersistent() || value.IsExpired(time.Now()) {
+		return false
+	}
+	s.set(key, value.Persist())
+	return true
+}
@@ -0,0 +1,173 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDataStore_Ttl(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("city", datatype.NewString("Minsk", 0))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+
+	ttl, ok := dataStore.Ttl("name")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, ttl > 59*time.Second && ttl <= time.Minute)
+	ttl, ok = dataStore.Ttl("city")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, NoTtl, ttl)
+	_, ok = dataStore.Ttl("weight")
+	assert.Equal(t, false, ok, "Expired item has no TTL")
+	_, ok = dataStore.Ttl("absent")
+	assert.Equal(t, false, ok)
+}
+
+func TestDataStore_Expire(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.NewString("82.5kg", 2*time.Minute))
+	dataStore.Set("city", datatype.NewString("Minsk", 0))
+
+	item, ok := dataStore.Expire("name", time.Hour)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Ivan", item.Value)
+	assert.Equal(t, time.Hour, item.Ttl.Round(time.Second))
+	assert.Equal(t, []interface{}{"weight", "name", "city"}, dataStore.GetKeys(), "DeathTime ordering should be updated")
+
+	_, ok = dataStore.Expire("city", time.Minute)
+	assert.Equal(t, true, ok, "TTL could be set for item without TTL")
+	assert.Equal(t, 0, len(dataStore.shards[0].persistent))
+	assert.Equal(t, []interface{}{"city", "weight", "name"}, dataStore.GetKeys())
+
+	item, ok = dataStore.Expire("weight", -time.Second)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, item.IsExpired(time.Now()), "Not positive TTL removes the item")
+	assert.Equal(t, false, dataStore.Contains("weight"))
+	assert.Equal(t, OperationDelete, operations[len(operations)-1].Type)
+
+	_, ok = dataStore.Expire("absent", time.Minute)
+	assert.Equal(t, false, ok)
+}
+
+func TestDataStore_ExpireAt(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	deathTime := time.Now().Add(time.Hour)
+
+	item, ok := dataStore.ExpireAt("name", deathTime)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, deathTime.Equal(item.DeathTime))
+	value, _ := dataStore.Get("name")
+	assert.Equal(t, true, deathTime.Equal(value.(datatype.DataType).DeathTime))
+
+	_, ok = dataStore.ExpireAt("name", time.Now().Add(-time.Minute))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, false, dataStore.Contains("name"), "DeathTime in the past removes the item")
+	assert.Equal(t, int64(0), dataStore.Stats().Memory)
+}
+
+func TestDataStore_Persist(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("weight", datatype.NewString("82.5kg", -time.Second))
+
+	assert.Equal(t, true, dataStore.Persist("name"))
+	value, _ := dataStore.Get("name")
+	assert.Equal(t, true, value.(datatype.DataType).IsPersistent())
+	assert.Equal(t, false, dataStore.Persist("name"), "Item has no TTL already")
+	assert.Equal(t, false, dataStore.Persist("weight"), "Expired item could not be persisted")
+	assert.Equal(t, false, dataStore.Persist("absent"))
+}
+
+func TestDataStore_Get_sliding(t *testing.T) {
+	dataStore := NewDataStore()
+	var operations []Operation
+	dataStore.AddListener(func(operation Operation) {
+		operations = append(operations, operation)
+	})
+	session := datatype.NewString("token", 100*time.Millisecond)
+	session.Sliding = true
+	dataStore.Set("session", session)
+	dataStore.Set("name", datatype.NewString("Ivan", 150*time.Millisecond))
+
+	time.Sleep(60 * time.Millisecond)
+	value, ok := dataStore.Get("session")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, value.(datatype.DataType).DeathTime.After(session.DeathTime), "DeathTime should be pushed forward")
+	assert.Equal(t, []interface{}{"name", "session"}, dataStore.GetKeys(), "DeathTime ordering should be updated")
+	assert.Equal(t, OperationSlide, operations[len(operations)-1].Type)
+	assert.Equal(t, value, operations[len(operations)-1].Value)
+
+	time.Sleep(60 * time.Millisecond)
+	_, ok = dataStore.Get("session")
+	assert.Equal(t, true, ok, "Sliding item read in time should not expire")
+	time.Sleep(120 * time.Millisecond)
+	_, ok = dataStore.Get("session")
+	assert.Equal(t, false, ok, "Sliding item which was not read in time should expire")
+	_, ok = dataStore.Get("name")
+	assert.Equal(t, false, ok)
+}
+
+func TestDataStore_SlideTo(t *testing.T) {
+	dataStore := NewDataStore()
+	session := datatype.NewString("token", time.Minute)
+	session.Sliding = true
+	stored := dataStore.Set("session", session)
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	deathTime := stored.DeathTime.Add(time.Second)
+	assert.Equal(t, true, dataStore.SlideTo("session", deathTime))
+	value, _ := dataStore.Get("session")
+	assert.Equal(t, stored.Version, value.(datatype.DataType).Version, "Version should be kept")
+	assert.Equal(t, time.Minute, value.(datatype.DataType).Ttl, "Ttl should be kept")
+	assert.Equal(t, false, dataStore.SlideTo("session", stored.DeathTime), "DeathTime is not moved backward")
+	assert.Equal(t, false, dataStore.SlideTo("name", deathTime), "Only sliding item could be slid")
+	assert.Equal(t, false, dataStore.SlideTo("absent", deathTime))
+}
+
+func TestDataStore_Contains_sliding(t *testing.T) {
+	dataStore := NewDataStore(WithShards(1))
+	session := datatype.NewString("token", time.Minute)
+	session.Sliding = true
+	dataStore.Set("session", session)
+
+	assert.Equal(t, true, dataStore.Contains("session"))
+	value, _ := dataStore.shards[0].lookup("session")
+	assert.Equal(t, session.DeathTime, value.DeathTime, "Only Get pushes DeathTime forward")
+}
+
+func ExampleDataStore_Expire() {
+	storage := NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	item, ok := storage.Expire("name", time.Hour)
+	fmt.Println(item.Value, ok)
+	// Output: Ivan true
+}
+
+func BenchmarkDataStore_Get_sliding(b *testing.B) {
+	storage := NewDataStore()
+	session := datatype.NewString("token", time.Minute)
+	session.Sliding = true
+	storage.Set("session", session)
+
+	for n := 0; n < b.N; n++ {
+		storage.Get("session")
+	}
+}
+
+func BenchmarkDataStore_Expire(b *testing.B) {
+	storage := NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	for n := 0; n < b.N; n++ {
+		storage.Expire("name", time.Minute)
+	}
+}
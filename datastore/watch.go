@@ -0,0 +1,120 @@
+package datastore
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// EventType is a type of change of the key reported to Watcher.
+type EventType string
+
+const (
+	// EventSet means that item was saved under the key.
+	EventSet EventType = "set"
+	// EventDelete means that the key was deleted.
+	EventDelete EventType = "delete"
+	// EventExpire means that the key was removed because its DeathTime passed.
+	EventExpire EventType = "expire"
+	// EventEvict means that the key was evicted because the collection exceeded its size limits.
+	EventEvict EventType = "evict"
+	// EventClear means that all items were removed, it has no key.
+	EventClear EventType = "clear"
+)
+
+// Event describes change of the key. Version is a version of saved item, it is set only for EventSet.
+type Event struct {
+	Type    EventType `json:"type"`
+	Key     string    `json:"key,omitempty"`
+	Version uint64    `json:"version,omitempty"`
+}
+
+// Watcher receives events about changes of keys with its prefix.
+type Watcher struct {
+	hub    *hub
+	prefix string
+	events chan Event
+	closed bool
+}
+
+// Watch creates Watcher of keys starting with prefix (all keys for empty prefix) which buffers up to
+// bufferSize events. Events are sent without waiting for receiver: Watcher which buffer is full is closed,
+// so its receiver knows that events were lost and could drop everything it has cached.
+func (ds *DataStore) Watch(prefix string, bufferSize int) *Watcher {
+	ds.watchMutex.Lock()
+	defer ds.watchMutex.Unlock()
+	watcher := &Watcher{hub: ds.hub, prefix: prefix, events: make(chan Event, bufferSize)}
+	ds.watchers[watcher] = true
+	atomic.AddInt32(&ds.watcherCount, 1)
+	return watcher
+}
+
+// Events returns channel of events. It is closed when the watcher is closed by Close
+// or because its receiver didn't keep up with changes.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Close stops the watcher and closes its events.
+func (w *Watcher) Close() {
+	w.hub.watchMutex.Lock()
+	defer w.hub.watchMutex.Unlock()
+	w.hub.closeWatcher(w)
+}
+
+// notifyWatchers converts operation into events and sends them to watchers.
+// Watchers are locked separately from shards, so events of different shards are not interleaved.
+// The lock is not taken when there are no watchers, so writes of different shards don't contend for it.
+func (h *hub) notifyWatchers(operation Operation) {
+	if operation.Type == OperationSlide || atomic.LoadInt32(&h.watcherCount) == 0 {
+		return
+	}
+	h.watchMutex.Lock()
+	defer h.watchMutex.Unlock()
+	if len(h.watchers) == 0 {
+		return
+	}
+	var events []Event
+	switch operation.Type {
+	case OperationSet:
+		events = []Event{{Type: EventSet, Key: operation.Key, Version: operation.Value.Version}}
+	case OperationDelete:
+		events = []Event{{Type: EventDelete, Key: operation.Key}}
+	case OperationBatchDelete:
+		for _, key := range operation.Keys {
+			events = append(events, Event{Type: EventDelete, Key: key})
+		}
+	case OperationExpire:
+		events = []Event{{Type: EventExpire, Key: operation.Key}}
+	case OperationEvict:
+		events = []Event{{Type: EventEvict, Key: operation.Key}}
+	case OperationClear:
+		events = []Event{{Type: EventClear}}
+	}
+
+	for watcher := range h.watchers {
+		for _, event := range events {
+			if event.Type != EventClear && !strings.HasPrefix(event.Key, watcher.prefix) {
+				continue
+			}
+			select {
+			case watcher.events <- event:
+			default:
+				h.closeWatcher(watcher)
+			}
+			if watcher.closed {
+				break
+			}
+		}
+	}
+}
+
+// closeWatcher should be called under watchMutex.
+func (h *hub) closeWatcher(watcher *Watcher) {
+	if watcher.closed {
+		return
+	}
+	watcher.closed = true
+	delete(h.watchers, watcher)
+	atomic.AddInt32(&h.watcherCount, -1)
+	close(watcher.events)
+}
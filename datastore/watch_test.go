@@ -0,0 +1,130 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// receive returns events buffered by the watcher.
+func receive(watcher *Watcher) []Event {
+	events := []Event{}
+	for len(watcher.Events()) > 0 {
+		events = append(events, <-watcher.Events())
+	}
+	return events
+}
+
+func TestDataStore_Watch(t *testing.T) {
+	dataStore := NewDataStore()
+	watcher := dataStore.Watch("user:", 100)
+	defer watcher.Close()
+
+	stored := dataStore.Set("user:1", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("city", datatype.NewString("Minsk", time.Minute))
+	dataStore.RPush("user:2", "admin")
+	listVersion := dataStore.Version("user:2")
+	dataStore.Delete("user:1")
+	dataStore.BatchDelete([]interface{}{"user:2", "city", "user:3"})
+	dataStore.Clear()
+
+	assert.Equal(t, []Event{
+		{Type: EventSet, Key: "user:1", Version: stored.Version},
+		{Type: EventSet, Key: "user:2", Version: listVersion},
+		{Type: EventDelete, Key: "user:1"},
+		{Type: EventDelete, Key: "user:2"},
+		{Type: EventClear},
+	}, receive(watcher))
+}
+
+func TestDataStore_Watch_expireAndEvict(t *testing.T) {
+	dataStore := NewDataStore(WithMaxItems(2))
+	watcher := dataStore.Watch("", 100)
+	defer watcher.Close()
+
+	dataStore.Set("session", datatype.NewString("token", 10*time.Millisecond))
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, dataStore.RemoveExpired(0))
+	dataStore.Set("city", datatype.NewString("Minsk", time.Minute))
+	dataStore.Set("age", datatype.NewString("27", time.Minute))
+
+	types := []EventType{}
+	keys := []string{}
+	for _, event := range receive(watcher) {
+		types = append(types, event.Type)
+		keys = append(keys, event.Key)
+	}
+	assert.Equal(t, []EventType{EventSet, EventSet, EventExpire, EventSet, EventSet, EventEvict}, types)
+	assert.Equal(t, []string{"session", "name", "session", "city", "age", "name"}, keys)
+}
+
+func TestDataStore_Watch_lazyExpiration(t *testing.T) {
+	dataStore := NewDataStore()
+	dataStore.Set("session", datatype.NewString("token", 10*time.Millisecond))
+	watcher := dataStore.Watch("session", 100)
+	defer watcher.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok := dataStore.Get("session")
+	assert.Equal(t, false, ok)
+	assert.Equal(t, []Event{{Type: EventExpire, Key: "session"}}, receive(watcher))
+}
+
+func TestDataStore_Watch_slidingRead(t *testing.T) {
+	dataStore := NewDataStore()
+	session := datatype.NewString("token", time.Minute)
+	session.Sliding = true
+	dataStore.Set("session", session)
+	watcher := dataStore.Watch("session", 100)
+	defer watcher.Close()
+
+	time.Sleep(time.Millisecond)
+	_, ok := dataStore.Get("session")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []Event{}, receive(watcher), "Read of sliding item should not be reported")
+}
+
+func TestWatcher_Close(t *testing.T) {
+	dataStore := NewDataStore()
+	watcher := dataStore.Watch("", 100)
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	watcher.Close()
+	watcher.Close()
+	dataStore.Set("city", datatype.NewString("Minsk", time.Minute))
+	event, ok := <-watcher.Events()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "name", event.Key, "Buffered events are kept")
+	_, ok = <-watcher.Events()
+	assert.Equal(t, false, ok)
+}
+
+func TestWatcher_slowReceiver(t *testing.T) {
+	dataStore := NewDataStore()
+	slow := dataStore.Watch("", 1)
+	fast := dataStore.Watch("", 10)
+	defer fast.Close()
+
+	dataStore.Set("name", datatype.NewString("Ivan", time.Minute))
+	dataStore.Set("city", datatype.NewString("Minsk", time.Minute))
+
+	assert.Equal(t, 2, len(receive(fast)))
+	<-slow.Events()
+	_, ok := <-slow.Events()
+	assert.Equal(t, false, ok, "Watcher with full buffer is closed")
+}
+
+func ExampleDataStore_Watch() {
+	dataStore := NewDataStore()
+	watcher := dataStore.Watch("user:", 10)
+	defer watcher.Close()
+
+	dataStore.Set("user:1", datatype.NewString("Ivan", 0))
+	dataStore.Delete("user:1")
+	fmt.Println((<-watcher.Events()).Type, (<-watcher.Events()).Type)
+	// Output:
+	// set delete
+}
@@ -3,26 +3,65 @@ package datatype
 import "time"
 
 // DataType represents cache item with Value stored inside, Ttl and DeathTime fields.
+// DeathTime of Sliding item is pushed forward by its Ttl each time the item is read.
+// Version and Modified time are assigned by storage on each write of the item,
+// they are used for optimistic concurrency and conditional reads.
 type DataType struct {
 	Value     interface{}   `json:"value"`
 	Ttl       time.Duration `json:"ttl"`
 	DeathTime time.Time     `json:"deathTime"`
+	Sliding   bool          `json:"sliding,omitempty"`
+	Version   uint64        `json:"version,omitempty"`
+	Modified  time.Time     `json:"-"`
 }
 
 // NewString creates DataType item with string value inside.
-// Its DeathTime = (current time) + (provided TTL).
+// Its DeathTime = (current time) + (provided TTL), zero TTL means that item never expires.
 func NewString(value string, ttl time.Duration) DataType {
-	return DataType{value, ttl, time.Now().Add(ttl)}
+	return DataType{Value: value, Ttl: ttl, DeathTime: DeathTimeOf(ttl)}
 }
 
 // NewList creates DataType item with list value.
-// Its DeathTime = (current time) + (provided TTL).
+// Its DeathTime = (current time) + (provided TTL), zero TTL means that item never expires.
 func NewList(value []interface{}, ttl time.Duration) DataType {
-	return DataType{value, ttl, time.Now().Add(ttl)}
+	return DataType{Value: value, Ttl: ttl, DeathTime: DeathTimeOf(ttl)}
 }
 
 // NewDict creates DataType item with map value.
-// Its DeathTime = (current time) + (provided TTL).
+// Its DeathTime = (current time) + (provided TTL), zero TTL means that item never expires.
 func NewDict(value map[interface{}]interface{}, ttl time.Duration) DataType {
-	return DataType{value, ttl, time.Now().Add(ttl)}
+	return DataType{Value: value, Ttl: ttl, DeathTime: DeathTimeOf(ttl)}
+}
+
+// DeathTimeOf returns DeathTime of item with provided TTL created now.
+// Zero TTL means that item never expires, its DeathTime is zero time.
+func DeathTimeOf(ttl time.Duration) time.Time {
+	if ttl == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// IsPersistent returns flag that item never expires.
+func (dt DataType) IsPersistent() bool {
+	return dt.DeathTime.IsZero()
+}
+
+// IsExpired returns flag is DeathTime of the item passed. Persistent item is never expired.
+func (dt DataType) IsExpired(now time.Time) bool {
+	return !dt.IsPersistent() && !dt.DeathTime.After(now)
+}
+
+// Persist returns copy of the item which never expires.
+func (dt DataType) Persist() DataType {
+	return DataType{Value: dt.Value}
+}
+
+// Slide returns copy of the item with DeathTime pushed forward by Ttl from provided time.
+// Only Sliding items with TTL are changed.
+func (dt DataType) Slide(now time.Time) DataType {
+	if dt.Sliding && !dt.IsPersistent() {
+		dt.DeathTime = now.Add(dt.Ttl)
+	}
+	return dt
 }
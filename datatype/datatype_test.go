@@ -56,3 +56,37 @@ func ExampleNewDict() {
 	duration := time.Minute
 	NewDict(value, duration)
 }
+
+func TestNewString_withoutTtl(t *testing.T) {
+	dataType := NewString("value", 0)
+	assert.Equal(t, true, dataType.IsPersistent())
+	assert.Equal(t, true, dataType.DeathTime.IsZero())
+	assert.Equal(t, false, dataType.IsExpired(time.Now().Add(time.Hour)))
+}
+
+func TestDataType_IsExpired(t *testing.T) {
+	dataType := NewString("value", time.Minute)
+	assert.Equal(t, false, dataType.IsPersistent())
+	assert.Equal(t, false, dataType.IsExpired(time.Now()))
+	assert.Equal(t, true, dataType.IsExpired(time.Now().Add(time.Minute)))
+}
+
+func TestDataType_Persist(t *testing.T) {
+	dataType := NewString("value", time.Minute).Persist()
+	assert.Equal(t, "value", dataType.Value)
+	assert.Equal(t, time.Duration(0), dataType.Ttl)
+	assert.Equal(t, true, dataType.IsPersistent())
+}
+
+func TestDataType_Slide(t *testing.T) {
+	now := time.Now().Add(time.Hour)
+	dataType := NewString("value", time.Minute)
+	assert.Equal(t, dataType, dataType.Slide(now), "Not sliding item is not changed")
+
+	dataType.Sliding = true
+	assert.Equal(t, now.Add(time.Minute), dataType.Slide(now).DeathTime)
+
+	persistent := NewString("value", 0)
+	persistent.Sliding = true
+	assert.Equal(t, true, persistent.Slide(now).IsPersistent(), "Item without TTL never expires")
+}
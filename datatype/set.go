@@ -0,0 +1,46 @@
+package datatype
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Set is a set of unique string members. It is encoded into json as sorted array of members.
+// Map values are always true, bool is used instead of empty struct to keep Set encodable by gob.
+type Set map[string]bool
+
+// NewSet creates DataType item with set of provided members.
+// Its DeathTime = (current time) + (provided TTL), zero TTL means that item never expires.
+func NewSet(members []string, ttl time.Duration) DataType {
+	return DataType{Value: NewSetOf(members...), Ttl: ttl, DeathTime: DeathTimeOf(ttl)}
+}
+
+// NewSetOf creates Set with provided members, duplicates are ignored.
+func NewSetOf(members ...string) Set {
+	set := make(Set, len(members))
+	for _, member := range members {
+		set[member] = true
+	}
+	return set
+}
+
+// Contains returns flag is the member present in the set.
+func (s Set) Contains(member string) bool {
+	return s[member]
+}
+
+// Members returns sorted members of the set.
+func (s Set) Members() []string {
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// MarshalJSON encodes the set as sorted array of members.
+func (s Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Members())
+}
@@ -0,0 +1,39 @@
+package datatype
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewSet(t *testing.T) {
+	dataType := NewSet([]string{"dark-mode", "beta", "dark-mode"}, time.Minute)
+	assert.Equal(t, Set{"dark-mode": true, "beta": true}, dataType.Value)
+	assert.Equal(t, time.Minute, dataType.Ttl)
+	assert.Equal(t, false, dataType.IsPersistent())
+}
+
+func TestSet_Members(t *testing.T) {
+	set := NewSetOf("dark-mode", "beta")
+	assert.Equal(t, []string{"beta", "dark-mode"}, set.Members())
+	assert.Equal(t, true, set.Contains("beta"))
+	assert.Equal(t, false, set.Contains("alpha"))
+	assert.Equal(t, []string{}, NewSetOf().Members())
+}
+
+func TestSet_MarshalJSON(t *testing.T) {
+	body, err := json.Marshal(NewSet([]string{"dark-mode", "beta"}, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"value":["beta","dark-mode"],"ttl":0,"deathTime":"0001-01-01T00:00:00Z"}`, string(body))
+}
+
+func TestSet_EstimateSize(t *testing.T) {
+	small := NewSet([]string{"beta"}, time.Minute)
+	big := NewSet([]string{"beta", "dark-mode"}, time.Minute)
+	assert.Equal(t, true, big.EstimateSize() > small.EstimateSize()+int64(len("dark-mode")))
+}
+
+func ExampleNewSet() {
+	NewSet([]string{"dark-mode", "beta"}, time.Minute)
+}
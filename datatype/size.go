@@ -0,0 +1,66 @@
+package datatype
+
+import "unsafe"
+
+// Approximate sizes in bytes of Go runtime structures used for memory usage estimation.
+const (
+	interfaceSize = int64(unsafe.Sizeof(interface{}(nil)))
+	stringSize    = int64(unsafe.Sizeof(""))
+	sliceSize     = int64(unsafe.Sizeof([]interface{}{}))
+	dataTypeSize  = int64(unsafe.Sizeof(DataType{}))
+	// mapEntryOverhead approximates bucket overhead of a Go map per entry
+	mapEntryOverhead = 16
+	// mapSize approximates header size of a Go map
+	mapSize = 48
+)
+
+// EstimateSize returns approximate amount of memory in bytes occupied by the item.
+// Estimation is rough, it is intended to be used for memory limits only.
+func (dt DataType) EstimateSize() int64 {
+	return dataTypeSize + estimateValueSize(dt.Value)
+}
+
+// EstimateListValueSize returns approximate amount of memory occupied by the list value including its slot in the list.
+// Size of the list which capacity equals to its length is the size of empty list plus sizes of its values.
+func EstimateListValueSize(value interface{}) int64 {
+	return interfaceSize + estimateValueSize(value)
+}
+
+// estimateValueSize returns approximate amount of memory referenced by value stored in interface.
+func estimateValueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return stringSize + int64(len(v))
+	case []interface{}:
+		size := sliceSize + int64(cap(v))*interfaceSize
+		for _, item := range v {
+			size += estimateValueSize(item)
+		}
+		return size
+	case map[string]interface{}:
+		size := int64(mapSize)
+		for key, item := range v {
+			size += mapEntryOverhead + stringSize + int64(len(key)) + interfaceSize + estimateValueSize(item)
+		}
+		return size
+	case Set:
+		size := int64(mapSize)
+		for member := range v {
+			size += mapEntryOverhead + stringSize + int64(len(member)) + 1
+		}
+		return size
+	case *SortedSet:
+		return v.estimateSize()
+	case map[interface{}]interface{}:
+		size := int64(mapSize)
+		for key, item := range v {
+			size += mapEntryOverhead + 2*interfaceSize + estimateValueSize(key) + estimateValueSize(item)
+		}
+		return size
+	default:
+		// numbers, booleans and other small scalar values
+		return 8
+	}
+}
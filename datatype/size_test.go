@@ -0,0 +1,40 @@
+package datatype
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDataType_EstimateSize(t *testing.T) {
+	short := NewString("Ivan", time.Minute)
+	long := NewString("Ivan Ivanovich Ivanov", time.Minute)
+	assert.Equal(t, int64(len("Ivan Ivanovich Ivanov")-len("Ivan")), long.EstimateSize()-short.EstimateSize())
+
+	list := NewList([]interface{}{"Xiaomi", "Samsung"}, time.Minute)
+	assert.Equal(t, true, list.EstimateSize() > dataTypeSize+int64(len("XiaomiSamsung")))
+
+	dict := NewDict(map[interface{}]interface{}{2: "Visa", 3: "Maestro"}, time.Minute)
+	assert.Equal(t, true, dict.EstimateSize() > dataTypeSize+int64(len("VisaMaestro")))
+
+	nested := DataType{Value: map[string]interface{}{"cards": []interface{}{"Visa"}, "age": 27.0}}
+	assert.Equal(t, true, nested.EstimateSize() > dataTypeSize+int64(len("cardsVisaage")))
+}
+
+func TestEstimateListValueSize(t *testing.T) {
+	empty := NewList([]interface{}{}, time.Minute)
+	list := NewList([]interface{}{"Xiaomi", 7.0}, time.Minute)
+	assert.Equal(t, list.EstimateSize(), empty.EstimateSize()+EstimateListValueSize("Xiaomi")+EstimateListValueSize(7.0))
+}
+
+func ExampleDataType_EstimateSize() {
+	NewString("Ivan", time.Minute).EstimateSize()
+}
+
+func BenchmarkDataType_EstimateSize(b *testing.B) {
+	dict := NewDict(map[interface{}]interface{}{2: "Visa", 3: "Maestro"}, time.Minute)
+
+	for n := 0; n < b.N; n++ {
+		dict.EstimateSize()
+	}
+}
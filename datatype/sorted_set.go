@@ -0,0 +1,238 @@
+package datatype
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/umpc/go-sortedmap"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// ScoredMember is a member of SortedSet with its score.
+type ScoredMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// ScoreRange is a range of scores, bounds are inclusive unless they are marked as exclusive.
+type ScoreRange struct {
+	Min          float64
+	Max          float64
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+// SortedSet is a set of unique string members ordered by their scores, members with equal scores
+// are ordered lexicographically. It is encoded into json as array of ScoredMember in ascending order.
+//
+// Unlike other values SortedSet is changed in place, so updates of big sorted sets don't copy them.
+// SortedSet is concurrency-safe, so it could be read (for example encoded) while it is changed.
+type SortedSet struct {
+	mutex  sync.RWMutex
+	scores map[string]float64
+	order  *sortedmap.SortedMap
+	// size is an estimated memory usage of members, it is maintained on each change
+	size int64
+}
+
+// scoreKey is a position of member in the order of SortedSet.
+// Bound is not zero only for keys used as bounds of iteration: negative bound precedes all members
+// with the same score, positive one follows them.
+type scoreKey struct {
+	score  float64
+	bound  int8
+	member string
+}
+
+func lessScoreKey(i, j interface{}) bool {
+	a, b := i.(scoreKey), j.(scoreKey)
+	if a.score != b.score {
+		return a.score < b.score
+	}
+	if a.bound != b.bound {
+		return a.bound < b.bound
+	}
+	return a.member < b.member
+}
+
+// NewSortedSet creates DataType item with sorted set of provided members and their scores.
+// Its DeathTime = (current time) + (provided TTL), zero TTL means that item never expires.
+func NewSortedSet(members map[string]float64, ttl time.Duration) DataType {
+	set := NewSortedSetOf()
+	for member, score := range members {
+		set.Add(member, score)
+	}
+	return DataType{Value: set, Ttl: ttl, DeathTime: DeathTimeOf(ttl)}
+}
+
+// NewSortedSetOf creates SortedSet with provided members.
+func NewSortedSetOf(members ...ScoredMember) *SortedSet {
+	set := &SortedSet{scores: make(map[string]float64, len(members)), order: sortedmap.New(len(members), lessScoreKey)}
+	for _, member := range members {
+		set.Add(member.Member, member.Score)
+	}
+	return set
+}
+
+// Add sets score of the member. Returns true if the member was not present in the set.
+func (s *SortedSet) Add(member string, score float64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	old, ok := s.scores[member]
+	if ok {
+		if old == score {
+			return false
+		}
+		s.order.Delete(member)
+	} else {
+		s.size += sortedSetMemberSize(member)
+	}
+	s.scores[member] = score
+	s.order.Insert(member, scoreKey{score: score, member: member})
+	return !ok
+}
+
+// Remove removes the member. Returns false if there is no such member.
+func (s *SortedSet) Remove(member string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.scores[member]; !ok {
+		return false
+	}
+	delete(s.scores, member)
+	s.order.Delete(member)
+	s.size -= sortedSetMemberSize(member)
+	return true
+}
+
+// Score returns score of the member. Returns false if there is no such member.
+func (s *SortedSet) Score(member string) (float64, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	score, ok := s.scores[member]
+	return score, ok
+}
+
+// Len returns amount of members.
+func (s *SortedSet) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.scores)
+}
+
+// Rank returns position of the member in ascending order (descending one if reversed is true), starting from 0.
+// Returns false if there is no such member.
+func (s *SortedSet) Rank(member string, reversed bool) (int, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if _, ok := s.scores[member]; !ok {
+		return 0, false
+	}
+	rank := 0
+	s.order.IterFunc(reversed, func(record sortedmap.Record) bool {
+		if record.Key == member {
+			return false
+		}
+		rank++
+		return true
+	})
+	return rank, true
+}
+
+// Range returns members with positions from "from" inclusive to "to" exclusive in ascending order
+// (descending one if reversed is true).
+func (s *SortedSet) Range(from, to int, reversed bool) []ScoredMember {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if from >= to {
+		return []ScoredMember{}
+	}
+	result := make([]ScoredMember, 0, to-from)
+	position := 0
+	s.order.IterFunc(reversed, func(record sortedmap.Record) bool {
+		if position >= from {
+			result = append(result, toScoredMember(record))
+		}
+		position++
+		return position < to
+	})
+	return result
+}
+
+// RangeByScore returns members with scores within the range in ascending order (descending one if reversed is true).
+// First offset members are skipped, negative count means that all remaining members are returned.
+func (s *SortedSet) RangeByScore(scoreRange ScoreRange, reversed bool, offset, count int) []ScoredMember {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	lower := scoreKey{score: scoreRange.Min, bound: -1}
+	if scoreRange.MinExclusive {
+		lower.bound = 1
+	}
+	upper := scoreKey{score: scoreRange.Max, bound: 1}
+	if scoreRange.MaxExclusive {
+		upper.bound = -1
+	}
+
+	result := make([]ScoredMember, 0)
+	if count == 0 || lessScoreKey(upper, lower) {
+		return result
+	}
+	position := 0
+	// error is returned when there are no members within the bounds, result is empty in this case
+	s.order.BoundedIterFunc(reversed, lower, upper, func(record sortedmap.Record) bool {
+		if position >= offset {
+			result = append(result, toScoredMember(record))
+		}
+		position++
+		return count < 0 || len(result) < count
+	})
+	return result
+}
+
+// Members returns all members in ascending order.
+func (s *SortedSet) Members() []ScoredMember {
+	return s.Range(0, s.Len(), false)
+}
+
+// MarshalJSON encodes the sorted set as array of members with scores in ascending order.
+func (s *SortedSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Members())
+}
+
+// GobEncode encodes the sorted set as slice of members with scores.
+func (s *SortedSet) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(s.Members())
+	return buf.Bytes(), err
+}
+
+// GobDecode restores the sorted set encoded by GobEncode.
+func (s *SortedSet) GobDecode(data []byte) error {
+	var members []ScoredMember
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&members); err != nil {
+		return err
+	}
+	*s = SortedSet{scores: make(map[string]float64, len(members)), order: sortedmap.New(len(members), lessScoreKey)}
+	for _, member := range members {
+		s.Add(member.Member, member.Score)
+	}
+	return nil
+}
+
+func (s *SortedSet) estimateSize() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return mapSize + s.size
+}
+
+// sortedSetMemberSize approximates memory used by the member: entries of scores map and of the ordered map.
+func sortedSetMemberSize(member string) int64 {
+	return 2*mapEntryOverhead + stringSize + int64(len(member)) + 3*interfaceSize + int64(unsafe.Sizeof(scoreKey{}))
+}
+
+func toScoredMember(record sortedmap.Record) ScoredMember {
+	key := record.Val.(scoreKey)
+	return ScoredMember{Member: key.member, Score: key.score}
+}
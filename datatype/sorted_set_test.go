@@ -0,0 +1,118 @@
+package datatype
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func newLeaderboard() *SortedSet {
+	return NewSortedSetOf(
+		ScoredMember{"ivan", 150},
+		ScoredMember{"petr", 90},
+		ScoredMember{"anna", 150},
+		ScoredMember{"olga", 300},
+	)
+}
+
+func TestNewSortedSet(t *testing.T) {
+	dataType := NewSortedSet(map[string]float64{"ivan": 150, "petr": 90}, time.Minute)
+	set := dataType.Value.(*SortedSet)
+	assert.Equal(t, 2, set.Len())
+	assert.Equal(t, []ScoredMember{{"petr", 90}, {"ivan", 150}}, set.Members())
+	assert.Equal(t, time.Minute, dataType.Ttl)
+}
+
+func TestSortedSet_Add_Remove(t *testing.T) {
+	set := newLeaderboard()
+
+	assert.Equal(t, false, set.Add("petr", 400), "Score of existing member is updated")
+	assert.Equal(t, true, set.Add("oleg", 10))
+	score, ok := set.Score("petr")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 400.0, score)
+	assert.Equal(t, "petr", set.Range(0, 1, true)[0].Member)
+
+	assert.Equal(t, true, set.Remove("petr"))
+	assert.Equal(t, false, set.Remove("petr"))
+	_, ok = set.Score("petr")
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 4, set.Len())
+}
+
+func TestSortedSet_Rank(t *testing.T) {
+	set := newLeaderboard()
+
+	rank, ok := set.Rank("petr", false)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 0, rank)
+	rank, _ = set.Rank("ivan", false)
+	assert.Equal(t, 2, rank, "Members with equal scores are ordered lexicographically")
+	rank, _ = set.Rank("olga", true)
+	assert.Equal(t, 0, rank)
+	_, ok = set.Rank("oleg", false)
+	assert.Equal(t, false, ok)
+}
+
+func TestSortedSet_Range(t *testing.T) {
+	set := newLeaderboard()
+
+	assert.Equal(t, []ScoredMember{{"anna", 150}, {"ivan", 150}}, set.Range(1, 3, false))
+	assert.Equal(t, []ScoredMember{{"olga", 300}, {"ivan", 150}}, set.Range(0, 2, true))
+	assert.Equal(t, []ScoredMember{}, set.Range(0, 0, false))
+}
+
+func TestSortedSet_RangeByScore(t *testing.T) {
+	set := newLeaderboard()
+
+	assert.Equal(t, []ScoredMember{{"petr", 90}, {"anna", 150}, {"ivan", 150}},
+		set.RangeByScore(ScoreRange{Min: 90, Max: 150}, false, 0, -1))
+	assert.Equal(t, []ScoredMember{{"anna", 150}, {"ivan", 150}},
+		set.RangeByScore(ScoreRange{Min: 90, Max: 300, MinExclusive: true, MaxExclusive: true}, false, 0, -1))
+	assert.Equal(t, []ScoredMember{{"ivan", 150}, {"anna", 150}},
+		set.RangeByScore(ScoreRange{Min: math.Inf(-1), Max: 200}, true, 0, 2))
+	assert.Equal(t, []ScoredMember{{"ivan", 150}},
+		set.RangeByScore(ScoreRange{Min: math.Inf(-1), Max: math.Inf(1)}, false, 2, 1))
+	assert.Equal(t, []ScoredMember{}, set.RangeByScore(ScoreRange{Min: 200, Max: 100}, false, 0, -1))
+	assert.Equal(t, []ScoredMember{}, set.RangeByScore(ScoreRange{Min: 160, Max: 200}, false, 0, -1))
+}
+
+func TestSortedSet_encoding(t *testing.T) {
+	set := newLeaderboard()
+
+	body, err := json.Marshal(NewSortedSet(map[string]float64{"ivan": 150}, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"value":[{"member":"ivan","score":150}],"ttl":0,"deathTime":"0001-01-01T00:00:00Z"}`, string(body))
+
+	var buf bytes.Buffer
+	assert.Nil(t, gob.NewEncoder(&buf).Encode(set))
+	restored := &SortedSet{}
+	assert.Nil(t, gob.NewDecoder(&buf).Decode(restored))
+	assert.Equal(t, set.Members(), restored.Members())
+	assert.Equal(t, set.estimateSize(), restored.estimateSize())
+}
+
+func TestSortedSet_EstimateSize(t *testing.T) {
+	set := NewSortedSet(map[string]float64{"ivan": 150}, time.Minute)
+	size := set.EstimateSize()
+	set.Value.(*SortedSet).Add("petr", 90)
+	assert.Equal(t, true, set.EstimateSize() > size+int64(len("petr")))
+	set.Value.(*SortedSet).Remove("petr")
+	assert.Equal(t, size, set.EstimateSize())
+}
+
+func ExampleNewSortedSet() {
+	NewSortedSet(map[string]float64{"ivan": 150, "petr": 90}, time.Minute)
+}
+
+func BenchmarkSortedSet_Add(b *testing.B) {
+	set := NewSortedSetOf()
+
+	for n := 0; n < b.N; n++ {
+		set.Add("ivan", float64(n%1000))
+	}
+}
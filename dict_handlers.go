@@ -0,0 +1,107 @@
+package main
+
+import (
+	"github.com/gorilla/mux"
+	json "github.com/json-iterator/go"
+	"net/http"
+	"strconv"
+)
+
+// ReadFields returns all fields of the dict as json object, or only sorted names of the fields when "keys" query
+// parameter is true.
+func ReadFields(writer http.ResponseWriter, request *http.Request) {
+	key := mux.Vars(request)["key"]
+	if keysOnly, _ := strconv.ParseBool(request.URL.Query().Get("keys")); keysOnly {
+		fields, err := Commands.HKeys(key)
+		if err != nil {
+			populateResponseWriter(writer, storageErrorStatus(err))
+			return
+		}
+		writeJson(writer, http.StatusOK, fields)
+		return
+	}
+
+	dict, err := Commands.HGetAll(key)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, dict)
+}
+
+// ReadField returns value of the dict field.
+func ReadField(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	value, ok, err := Commands.HGet(vars["key"], vars["field"])
+	writeListValue(writer, value, ok, err)
+}
+
+// CheckField responds with 200 status code when the dict field exists and with 404 otherwise.
+func CheckField(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	exists, err := Commands.HExists(vars["key"], vars["field"])
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case !exists:
+		populateResponseWriter(writer, http.StatusNotFound)
+	default:
+		populateResponseWriter(writer, http.StatusOK)
+	}
+}
+
+// UpdateField sets json value from request body to the dict field.
+// Responds with 201 status code when the field is added and with 204 when existing field is updated.
+func UpdateField(writer http.ResponseWriter, request *http.Request) {
+	var value interface{}
+	if err := json.NewDecoder(request.Body).Decode(&value); err != nil {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(request)
+	added, err := Commands.HSet(vars["key"], map[string]interface{}{vars["field"]: value})
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case added > 0:
+		populateResponseWriter(writer, http.StatusCreated)
+	default:
+		populateResponseWriter(writer, http.StatusNoContent)
+	}
+}
+
+// DeleteField removes the dict field.
+func DeleteField(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	removed, err := Commands.HDel(vars["key"], vars["field"])
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case removed == 0:
+		populateResponseWriter(writer, http.StatusNotFound)
+	default:
+		populateResponseWriter(writer, http.StatusNoContent)
+	}
+}
+
+// IncrementField increments integer value of the dict field by "by" query parameter (1 by default)
+// and returns new value.
+func IncrementField(writer http.ResponseWriter, request *http.Request) {
+	delta := int64(1)
+	if value := request.URL.Query().Get("by"); value != "" {
+		var err error
+		if delta, err = strconv.ParseInt(value, 10, 64); err != nil {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+	}
+
+	vars := mux.Vars(request)
+	value, err := Commands.HIncrBy(vars["key"], vars["field"], delta)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, value)
+}
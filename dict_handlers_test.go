@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newDictServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}/fields", ReadFields).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/fields/{field}", ReadField).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/fields/{field}", CheckField).Methods(http.MethodHead)
+	router.HandleFunc("/items/{key}/fields/{field}", UpdateField).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/fields/{field}", DeleteField).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/fields/{field}/incr", IncrementField).Methods(http.MethodPost)
+	return httptest.NewServer(router)
+}
+
+func TestUpdateField_ReadField(t *testing.T) {
+	Storage.Clear()
+	server := newDictServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPut, server.URL+"/items/marks/fields/Math", `"9"`)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/marks/fields/Math", `10`)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/marks/fields/English", `"7"`)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/marks/fields/Math", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `10`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/marks/fields/Physics", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/marks/fields/Math", `{`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestReadFields(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("marks", datatype.NewDict(map[interface{}]interface{}{"Math": "9", "English": "7"}, time.Minute))
+	server := newDictServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/items/marks/fields", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var body map[string]interface{}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, map[string]interface{}{"Math": "9", "English": "7"}, body)
+	response = doRequest(t, http.MethodGet, server.URL+"/items/marks/fields?keys=true", "")
+	assert.Equal(t, `["English","Math"]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/absent/fields", "")
+	assert.Equal(t, `{}`, readBody(t, response))
+}
+
+func TestCheckField_DeleteField(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("marks", datatype.NewDict(map[interface{}]interface{}{"Math": "9"}, time.Minute))
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server := newDictServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodHead, server.URL+"/items/marks/fields/Math", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/marks/fields/Math", "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodHead, server.URL+"/items/marks/fields/Math", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/marks/fields/Math", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, false, Storage.Contains("marks"), "Empty dict is removed")
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/name/fields/first", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func TestIncrementField(t *testing.T) {
+	Storage.Clear()
+	server := newDictServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/counters/fields/visits/incr", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `1`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/counters/fields/visits/incr?by=-5", "")
+	assert.Equal(t, `-4`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/counters/fields/visits/incr?by=many", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	doRequest(t, http.MethodPut, server.URL+"/items/counters/fields/title", `"main"`)
+	response = doRequest(t, http.MethodPost, server.URL+"/items/counters/fields/title/incr", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/gorilla/mux"
+	json "github.com/json-iterator/go"
+	"net/http"
+	"strconv"
+)
+
+// lengthResponse is a body of responses which return length of collection.
+type lengthResponse struct {
+	Length int `json:"length"`
+}
+
+// ReadList returns values of the list between "start" and "stop" indexes inclusive (whole list by default).
+func ReadList(writer http.ResponseWriter, request *http.Request) {
+	start, stop, ok := parseRangeParams(request)
+	if !ok {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+	values, err := Commands.LRange(mux.Vars(request)["key"], start, stop)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, values)
+}
+
+// ReadListLength returns length of the list.
+func ReadListLength(writer http.ResponseWriter, request *http.Request) {
+	length, err := Commands.LLen(mux.Vars(request)["key"])
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, lengthResponse{length})
+}
+
+// TrimList leaves only values of the list between "start" and "stop" indexes inclusive.
+func TrimList(writer http.ResponseWriter, request *http.Request) {
+	start, stop, ok := parseRangeParams(request)
+	if !ok {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+	if err := Commands.LTrim(mux.Vars(request)["key"], start, stop); err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	populateResponseWriter(writer, http.StatusNoContent)
+}
+
+// PushList pushes values from json array in request body to the head or to the tail of the list
+// and returns new length of the list.
+func PushList(writer http.ResponseWriter, request *http.Request) {
+	var values []interface{}
+	if err := json.NewDecoder(request.Body).Decode(&values); err != nil || len(values) == 0 {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(request)
+	push := Commands.RPush
+	if vars["end"] == "head" {
+		push = Commands.LPush
+	}
+	length, err := push(vars["key"], values...)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, lengthResponse{length})
+}
+
+// PopList removes and returns value from the head or from the tail of the list.
+func PopList(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	pop := Commands.RPop
+	if vars["end"] == "head" {
+		pop = Commands.LPop
+	}
+	value, ok, err := pop(vars["key"])
+	writeListValue(writer, value, ok, err)
+}
+
+// ReadListItem returns value of the list with provided index.
+func ReadListItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	index, _ := strconv.Atoi(vars["index"])
+	value, ok, err := Commands.LIndex(vars["key"], index)
+	writeListValue(writer, value, ok, err)
+}
+
+// UpdateListItem replaces value of the list with provided index by json value from request body.
+func UpdateListItem(writer http.ResponseWriter, request *http.Request) {
+	var value interface{}
+	if err := json.NewDecoder(request.Body).Decode(&value); err != nil {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(request)
+	index, _ := strconv.Atoi(vars["index"])
+	if err := Commands.LSet(vars["key"], index, value); err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	populateResponseWriter(writer, http.StatusNoContent)
+}
+
+// writeListValue writes result of list operation which returns single value.
+func writeListValue(writer http.ResponseWriter, value interface{}, ok bool, err error) {
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case !ok:
+		populateResponseWriter(writer, http.StatusNotFound)
+	default:
+		writeJson(writer, http.StatusOK, value)
+	}
+}
+
+// parseRangeParams parses optional "start" and "stop" query parameters, whole range is used by default.
+func parseRangeParams(request *http.Request) (int, int, bool) {
+	start, stop := 0, -1
+	query := request.URL.Query()
+	var err error
+	if value := query.Get("start"); value != "" {
+		if start, err = strconv.Atoi(value); err != nil {
+			return 0, 0, false
+		}
+	}
+	if value := query.Get("stop"); value != "" {
+		if stop, err = strconv.Atoi(value); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, stop, true
+}
+
+// storageErrorStatus converts error returned by command executor into HTTP status code.
+func storageErrorStatus(err error) int {
+	switch err {
+	case command.ErrNotFound, command.ErrOutOfRange:
+		return http.StatusNotFound
+	case command.ErrWrongType, command.ErrNotInteger, command.ErrNotFloat:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newListServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}/list", ReadList).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/list/length", ReadListLength).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/list/trim", TrimList).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/list/{end:head|tail}", PushList).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/list/{end:head|tail}", PopList).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/list/{index:-?[0-9]+}", ReadListItem).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/list/{index:-?[0-9]+}", UpdateListItem).Methods(http.MethodPut)
+	return httptest.NewServer(router)
+}
+
+func readBody(t *testing.T, response *http.Response) string {
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestPushList_PopList(t *testing.T) {
+	Storage.Clear()
+	server := newListServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/phones/list/tail", `["Xiaomi", "Samsung"]`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"length":2}`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/phones/list/head", `["Nokia"]`)
+	assert.Equal(t, `{"length":3}`, readBody(t, response))
+
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/phones/list/head", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `"Nokia"`, readBody(t, response))
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/phones/list/tail", "")
+	assert.Equal(t, `"Samsung"`, readBody(t, response))
+	doRequest(t, http.MethodDelete, server.URL+"/items/phones/list/tail", "")
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/phones/list/tail", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode, "Empty list is removed")
+
+	response = doRequest(t, http.MethodPost, server.URL+"/items/phones/list/tail", `[]`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestReadList_TrimList(t *testing.T) {
+	Storage.Clear()
+	Storage.RPush("numbers", 0.0, 1.0, 2.0, 3.0)
+	server := newListServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/items/numbers/list", "")
+	assert.Equal(t, `[0,1,2,3]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/numbers/list?start=1&stop=-2", "")
+	assert.Equal(t, `[1,2]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/numbers/list?start=first", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = doRequest(t, http.MethodPost, server.URL+"/items/numbers/list/trim?start=2", "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodGet, server.URL+"/items/numbers/list/length", "")
+	assert.Equal(t, `{"length":2}`, readBody(t, response))
+}
+
+func TestReadListItem_UpdateListItem(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("phones", datatype.NewList([]interface{}{"Xiaomi", "Samsung"}, time.Minute))
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server := newListServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/items/phones/list/-1", "")
+	assert.Equal(t, `"Samsung"`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/phones/list/5", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/phones/list/0", `{"model": "Nokia 3310"}`)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	values, _ := Storage.LRange("phones", 0, 0)
+	var expected interface{}
+	json.Unmarshal([]byte(`{"model": "Nokia 3310"}`), &expected)
+	assert.Equal(t, []interface{}{expected}, values)
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/phones/list/5", `"Nokia"`)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response = doRequest(t, http.MethodGet, server.URL+"/items/name/list", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode, "Item is not a list")
+}
@@ -0,0 +1,513 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AppendOnlyLogVersion is a version of append-only log file format written by this package.
+const AppendOnlyLogVersion uint32 = 1
+
+// appendOnlyLogMagic is written at the beginning of each append-only log file.
+var appendOnlyLogMagic = [8]byte{'G', 'O', 'C', 'A', 'C', 'H', 'E', 'A'}
+
+// maxRecordLength limits size of a single record, bigger length means that log is damaged.
+const maxRecordLength = 512 * 1024 * 1024
+
+// ErrBadAppendOnlyLog is returned when file is not an append-only log file.
+var ErrBadAppendOnlyLog = errors.New("persistence: not an append-only log file")
+
+// FsyncPolicy defines how often append-only log is flushed to disk.
+type FsyncPolicy int
+
+const (
+	// FsyncAlways syncs file after each operation: slowest but safest policy.
+	FsyncAlways FsyncPolicy = iota
+	// FsyncEverySecond syncs file once per second, up to one second of writes could be lost.
+	FsyncEverySecond
+	// FsyncNever leaves syncing to operating system.
+	FsyncNever
+)
+
+// ParseFsyncPolicy converts policy name ("always", "everysec" or "no") to FsyncPolicy.
+func ParseFsyncPolicy(name string) (FsyncPolicy, error) {
+	switch name {
+	case "always":
+		return FsyncAlways, nil
+	case "everysec":
+		return FsyncEverySecond, nil
+	case "no":
+		return FsyncNever, nil
+	}
+	return 0, fmt.Errorf("persistence: unknown fsync policy %q", name)
+}
+
+// AppendOnlyLog writes each mutation of DataStore into log file, so it could be replayed on restart.
+// Log is compacted by Rewrite which replaces its content with the minimal set of operations.
+// AppendOnlyLog is concurrency-safe.
+type AppendOnlyLog struct {
+	mutex    sync.Mutex
+	storage  *datastore.DataStore
+	path     string
+	policy   FsyncPolicy
+	file     *os.File
+	size     int64
+	isDirty  bool
+	isClosed bool
+
+	// failure is the first error of appending operation to the log, see Err
+	failure error
+
+	// slides contains DeathTime of sliding items pushed forward by reads since last flush, see flushSlides
+	slides map[string]time.Time
+
+	// baseSize is a size of log after last rewrite, it is used to decide when next rewrite is needed
+	baseSize      int64
+	isRewriting   bool
+	rewriteBuffer [][]byte
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// OpenAppendOnlyLog replays existing log file into storage and starts logging of all storage mutations.
+// If log file does not exist, it is created with current content of storage.
+func OpenAppendOnlyLog(storage *datastore.DataStore, path string, policy FsyncPolicy) (*AppendOnlyLog, error) {
+	l := &AppendOnlyLog{
+		storage: storage,
+		path:    path,
+		policy:  policy,
+		slides:  make(map[string]time.Time),
+		stop:    make(chan struct{}),
+	}
+
+	count, err := l.replay()
+	if os.IsNotExist(err) {
+		err = l.Rewrite()
+	} else if err == nil {
+		log.Printf("Replayed %d operations from append-only log %s", count, path)
+		l.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	storage.AddListener(l.append)
+	l.done.Add(1)
+	go l.flushEverySecond()
+	return l, nil
+}
+
+// replay applies operations from log file to storage. Truncated or corrupted tail of the log is cut off.
+func (l *AppendOnlyLog) replay() (int, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+
+	if err := readAppendOnlyLogHeader(reader); err != nil {
+		return 0, fmt.Errorf("replaying %s: %w", l.path, err)
+	}
+	offset := int64(len(appendOnlyLogMagic) + 4)
+	count := 0
+	for {
+		operation, length, err := readRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Append-only log %s is damaged at offset %d (%v), truncating it", l.path, offset, err)
+			if err := os.Truncate(l.path, offset); err != nil {
+				return count, err
+			}
+			break
+		}
+		applyOperation(l.storage, operation)
+		offset += length
+		count++
+	}
+	l.size = offset
+	l.baseSize = offset
+	return count, nil
+}
+
+// applyOperation replays logged operation on storage. Already expired items are skipped.
+func applyOperation(storage *datastore.DataStore, operation datastore.Operation) {
+	switch operation.Type {
+	case datastore.OperationSet:
+		if !operation.Value.IsExpired(time.Now()) {
+			storage.Set(operation.Key, operation.Value)
+		} else {
+			storage.Delete(operation.Key)
+		}
+	case datastore.OperationDelete, datastore.OperationEvict, datastore.OperationExpire:
+		storage.Delete(operation.Key)
+	case datastore.OperationBatchDelete:
+		keys := make([]interface{}, len(operation.Keys))
+		for i, key := range operation.Keys {
+			keys[i] = key
+		}
+		storage.BatchDelete(keys)
+	case datastore.OperationClear:
+		storage.Clear()
+	case datastore.OperationSlide:
+		storage.SlideTo(operation.Key, operation.Value.DeathTime)
+	}
+}
+
+// append is a DataStore listener which writes operation to the log.
+// Operation which could not be encoded or written is reported by Err.
+// Sliding refreshes are frequent, so only the last DeathTime of each key is kept and written by flushSlides.
+func (l *AppendOnlyLog) append(operation datastore.Operation) {
+	if operation.Type == datastore.OperationSlide {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		if !l.isClosed {
+			l.slides[operation.Key] = operation.Value.DeathTime
+		}
+		return
+	}
+	record, err := encodeRecord(operation)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.isClosed {
+		return
+	}
+	// refresh preceding the operation must not be written after it
+	switch operation.Type {
+	case datastore.OperationClear:
+		l.slides = make(map[string]time.Time)
+	case datastore.OperationBatchDelete:
+		for _, key := range operation.Keys {
+			delete(l.slides, key)
+		}
+	default:
+		delete(l.slides, operation.Key)
+	}
+	if err != nil {
+		l.fail(fmt.Errorf("persistence: encoding of append-only log record for key %q: %w", operation.Key, err))
+		return
+	}
+	l.write(record)
+	if l.policy == FsyncAlways {
+		l.sync()
+	}
+}
+
+// flushSlides writes DeathTime of sliding items refreshed since last flush. Should be called under the mutex.
+func (l *AppendOnlyLog) flushSlides() {
+	if len(l.slides) == 0 {
+		return
+	}
+	for key, deathTime := range l.slides {
+		record, err := encodeRecord(datastore.Operation{Type: datastore.OperationSlide, Key: key, Value: datatype.DataType{DeathTime: deathTime}})
+		if err != nil {
+			l.fail(fmt.Errorf("persistence: encoding of append-only log record for key %q: %w", key, err))
+			continue
+		}
+		l.write(record)
+	}
+	l.slides = make(map[string]time.Time)
+	if l.policy == FsyncAlways {
+		l.sync()
+	}
+}
+
+// write appends record to the log file and to the buffer of running rewrite. Should be called under the mutex.
+func (l *AppendOnlyLog) write(record []byte) {
+	if l.isRewriting {
+		l.rewriteBuffer = append(l.rewriteBuffer, record)
+	}
+	if _, err := l.file.Write(record); err != nil {
+		l.fail(fmt.Errorf("persistence: writing of append-only log: %w", err))
+		return
+	}
+	l.size += int64(len(record))
+	l.isDirty = true
+}
+
+// fail records error of lost operation. Should be called under the mutex.
+func (l *AppendOnlyLog) fail(err error) {
+	log.Printf("Error during append-only log appending: %v", err)
+	if l.failure == nil {
+		l.failure = err
+	}
+}
+
+// Err returns the first error which caused loss of operation since the log was opened or last successful rewrite.
+// Log with such error doesn't contain all mutations of storage, Rewrite restores its consistency.
+func (l *AppendOnlyLog) Err() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.failure
+}
+
+// sync flushes log file to disk. Should be called under the mutex.
+func (l *AppendOnlyLog) sync() {
+	if !l.isDirty {
+		return
+	}
+	if err := l.file.Sync(); err != nil {
+		log.Printf("Error during append-only log syncing: %v", err)
+		return
+	}
+	l.isDirty = false
+}
+
+// flushEverySecond writes sliding refreshes and syncs file with FsyncEverySecond policy once per second.
+func (l *AppendOnlyLog) flushEverySecond() {
+	defer l.done.Done()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.mutex.Lock()
+			l.flushSlides()
+			if l.policy == FsyncEverySecond {
+				l.sync()
+			}
+			l.mutex.Unlock()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// Rewrite compacts the log: its content is replaced by set operations for items currently stored.
+// Storage remains available for reads and writes during rewrite.
+func (l *AppendOnlyLog) Rewrite() error {
+	l.mutex.Lock()
+	if l.isRewriting {
+		l.mutex.Unlock()
+		return errors.New("persistence: append-only log rewrite is already in progress")
+	}
+	l.isRewriting = true
+	l.rewriteBuffer = nil
+	failure := l.failure
+	l.mutex.Unlock()
+
+	file, err := l.writeCompactedLog()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.isRewriting = false
+	if err == nil && l.isClosed {
+		err = errors.New("persistence: append-only log is closed")
+		file.Close()
+		os.Remove(file.Name())
+	}
+	if err != nil {
+		l.rewriteBuffer = nil
+		return err
+	}
+
+	// Operations applied during rewrite could be already present in compacted log,
+	// replaying them twice is safe because each operation is idempotent
+	for _, record := range l.rewriteBuffer {
+		if _, err := file.Write(record); err != nil {
+			l.rewriteBuffer = nil
+			file.Close()
+			os.Remove(file.Name())
+			return err
+		}
+	}
+	l.rewriteBuffer = nil
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), l.path); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	l.size = info.Size()
+	l.baseSize = l.size
+	l.isDirty = false
+	if l.failure == failure {
+		// compacted log contains current content of storage, so operations lost before rewrite don't matter
+		l.failure = nil
+	}
+	return nil
+}
+
+// writeCompactedLog writes current storage content into temporary log file and returns it opened for appending.
+func (l *AppendOnlyLog) writeCompactedLog() (*os.File, error) {
+	file, err := ioutil.TempFile(filepath.Dir(l.path), filepath.Base(l.path)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+	fail := func(err error) (*os.File, error) {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := writeAppendOnlyLogHeader(writer); err != nil {
+		return fail(err)
+	}
+	now := time.Now()
+	for _, entry := range l.storage.Entries() {
+		if entry.Value.IsExpired(now) {
+			continue
+		}
+		record, err := encodeRecord(datastore.Operation{Type: datastore.OperationSet, Key: entry.Key, Value: entry.Value})
+		if err != nil {
+			return fail(err)
+		}
+		if _, err := writer.Write(record); err != nil {
+			return fail(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fail(err)
+	}
+	return file, nil
+}
+
+// NeedsRewrite reports whether log has grown at least by growthPercent since last rewrite and exceeds minSize.
+func (l *AppendOnlyLog) NeedsRewrite(minSize int64, growthPercent int) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return !l.isRewriting && l.size >= minSize && l.size >= l.baseSize+l.baseSize*int64(growthPercent)/100
+}
+
+// StartAutoRewrite checks log size with provided interval and rewrites it
+// when it exceeds minSize and has grown at least by growthPercent since last rewrite.
+func (l *AppendOnlyLog) StartAutoRewrite(interval time.Duration, minSize int64, growthPercent int) {
+	l.done.Add(1)
+	go func() {
+		defer l.done.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if l.NeedsRewrite(minSize, growthPercent) {
+					if err := l.Rewrite(); err != nil {
+						log.Printf("Error during append-only log rewrite: %v", err)
+					}
+				}
+			case <-l.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Size returns current size of log file in bytes.
+func (l *AppendOnlyLog) Size() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.size
+}
+
+// Close stops background activities, syncs and closes log file.
+// Storage mutations are not logged after Close. Error returned by Err is returned if closing succeeded.
+func (l *AppendOnlyLog) Close() error {
+	close(l.stop)
+	l.done.Wait()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.flushSlides()
+	l.sync()
+	l.isClosed = true
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	return l.failure
+}
+
+func writeAppendOnlyLogHeader(writer io.Writer) error {
+	if _, err := writer.Write(appendOnlyLogMagic[:]); err != nil {
+		return err
+	}
+	return binary.Write(writer, binary.BigEndian, AppendOnlyLogVersion)
+}
+
+func readAppendOnlyLogHeader(reader io.Reader) error {
+	var magic [8]byte
+	if _, err := io.ReadFull(reader, magic[:]); err != nil || magic != appendOnlyLogMagic {
+		return ErrBadAppendOnlyLog
+	}
+	var version uint32
+	if err := binary.Read(reader, binary.BigEndian, &version); err != nil {
+		return ErrBadAppendOnlyLog
+	}
+	if version != AppendOnlyLogVersion {
+		return ErrUnsupportedVersion
+	}
+	return nil
+}
+
+// encodeRecord encodes operation as record: payload length, payload CRC32 checksum and gob-encoded payload.
+func encodeRecord(operation datastore.Operation) ([]byte, error) {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(operation); err != nil {
+		return nil, err
+	}
+	record := make([]byte, 8, 8+payload.Len())
+	binary.BigEndian.PutUint32(record[0:4], uint32(payload.Len()))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload.Bytes()))
+	return append(record, payload.Bytes()...), nil
+}
+
+// readRecord reads one record and returns decoded operation with length of the record in bytes.
+// io.EOF is returned when there are no more records.
+func readRecord(reader io.Reader) (datastore.Operation, int64, error) {
+	var operation datastore.Operation
+	var header [8]byte
+	n, err := io.ReadFull(reader, header[:])
+	if err == io.EOF {
+		return operation, 0, io.EOF
+	}
+	if err != nil {
+		return operation, 0, fmt.Errorf("truncated record header (%d bytes)", n)
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordLength {
+		return operation, 0, errors.New("record length is too big")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return operation, 0, errors.New("truncated record")
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return operation, 0, errors.New("record checksum mismatch")
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&operation); err != nil {
+		return operation, 0, err
+	}
+	return operation, int64(len(header)) + int64(length), nil
+}
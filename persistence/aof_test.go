@@ -0,0 +1,255 @@
+package persistence
+
+import (
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func tempAofPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "cache.aof"), func() { os.RemoveAll(dir) }
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	policy, err := ParseFsyncPolicy("always")
+	assert.Nil(t, err)
+	assert.Equal(t, FsyncAlways, policy)
+	policy, _ = ParseFsyncPolicy("everysec")
+	assert.Equal(t, FsyncEverySecond, policy)
+	policy, _ = ParseFsyncPolicy("no")
+	assert.Equal(t, FsyncNever, policy)
+	_, err = ParseFsyncPolicy("sometimes")
+	assert.NotNil(t, err)
+}
+
+func TestAppendOnlyLog_replay(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	storage.Set("initial", datatype.NewString("value", time.Minute))
+	aof, err := OpenAppendOnlyLog(storage, path, FsyncAlways)
+	assert.Nil(t, err)
+
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	storage.Set("phones", datatype.NewList([]interface{}{"Xiaomi", "Samsung"}, time.Minute))
+	storage.Set("expired", datatype.NewString("value", -time.Second))
+	storage.Delete("weight")
+	storage.BatchDelete([]interface{}{"initial", "absent"})
+	assert.Nil(t, aof.Close())
+
+	restored := datastore.NewDataStore()
+	aof, err = OpenAppendOnlyLog(restored, path, FsyncNever)
+	assert.Nil(t, err)
+	defer aof.Close()
+	assert.Equal(t, 2, restored.Count())
+	value, _ := restored.Get("name")
+	assert.Equal(t, "Ivan", value.(datatype.DataType).Value)
+	value, _ = restored.Get("phones")
+	assert.Equal(t, []interface{}{"Xiaomi", "Samsung"}, value.(datatype.DataType).Value)
+}
+
+func TestAppendOnlyLog_replaySortedSet(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, err := OpenAppendOnlyLog(storage, path, FsyncNever)
+	assert.Nil(t, err)
+
+	storage.ZAdd("scores", map[string]float64{"Ivan": 10, "Petr": 7.5, "Olga": 3})
+	storage.ZRem("scores", "Olga")
+	assert.Nil(t, aof.Err())
+	assert.Nil(t, aof.Close())
+
+	restored := datastore.NewDataStore()
+	aof, err = OpenAppendOnlyLog(restored, path, FsyncNever)
+	assert.Nil(t, err)
+	members, _ := restored.ZRange("scores", 0, -1, false)
+	assert.Equal(t, []datatype.ScoredMember{{Member: "Petr", Score: 7.5}, {Member: "Ivan", Score: 10}}, members)
+
+	assert.Nil(t, aof.Rewrite(), "Sorted set should be written into compacted log")
+	aof.Close()
+	restored = datastore.NewDataStore()
+	aof, _ = OpenAppendOnlyLog(restored, path, FsyncNever)
+	defer aof.Close()
+	members, _ = restored.ZRange("scores", 0, -1, false)
+	assert.Equal(t, 2, len(members))
+}
+
+// unregistered is a type unknown to gob, so items holding it could not be logged.
+type unregistered struct {
+	Name string
+}
+
+func TestAppendOnlyLog_replaySlide(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, err := OpenAppendOnlyLog(storage, path, FsyncNever)
+	assert.Nil(t, err)
+
+	session := datatype.NewString("token", 200*time.Millisecond)
+	session.Sliding = true
+	storage.Set("session", session)
+	storage.Set("overwritten", session)
+	time.Sleep(100 * time.Millisecond)
+	slid, _ := storage.Get("session")
+	storage.Get("overwritten")
+	stored := storage.Set("overwritten", datatype.NewString("value", time.Minute))
+	assert.Nil(t, aof.Close())
+
+	restored := datastore.NewDataStore()
+	aof, err = OpenAppendOnlyLog(restored, path, FsyncNever)
+	assert.Nil(t, err)
+	defer aof.Close()
+	time.Sleep(150 * time.Millisecond)
+	value, ok := restored.Get("session")
+	assert.Equal(t, true, ok, "Session kept alive by read should not expire after restart")
+	assert.Equal(t, true, value.(datatype.DataType).DeathTime.After(slid.(datatype.DataType).DeathTime))
+	value, _ = restored.Get("overwritten")
+	assert.Equal(t, true, stored.DeathTime.Equal(value.(datatype.DataType).DeathTime), "Refresh should not be applied after later write")
+}
+
+func TestAppendOnlyLog_Err(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, err := OpenAppendOnlyLog(storage, path, FsyncNever)
+	assert.Nil(t, err)
+
+	storage.Set("name", datatype.DataType{Value: unregistered{"Ivan"}})
+	assert.NotNil(t, aof.Err(), "Lost operation should be reported")
+
+	storage.Delete("name")
+	assert.Nil(t, aof.Rewrite())
+	assert.Nil(t, aof.Err(), "Rewrite should restore consistency of the log")
+
+	storage.Set("name", datatype.DataType{Value: unregistered{"Ivan"}})
+	assert.NotNil(t, aof.Close(), "Lost operation should be reported by Close")
+}
+
+func TestAppendOnlyLog_replayClear(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, _ := OpenAppendOnlyLog(storage, path, FsyncNever)
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Clear()
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	aof.Close()
+
+	restored := datastore.NewDataStore()
+	aof, _ = OpenAppendOnlyLog(restored, path, FsyncNever)
+	defer aof.Close()
+	assert.Equal(t, []interface{}{"weight"}, restored.GetKeys())
+}
+
+func TestAppendOnlyLog_replayTruncatesDamagedTail(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, _ := OpenAppendOnlyLog(storage, path, FsyncAlways)
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	size := aof.Size()
+	aof.Close()
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	file.Write([]byte{0, 0, 0, 50, 1, 2})
+	file.Close()
+
+	restored := datastore.NewDataStore()
+	aof, err := OpenAppendOnlyLog(restored, path, FsyncAlways)
+	assert.Nil(t, err)
+	assert.Equal(t, true, restored.Contains("name"))
+	assert.Equal(t, size, aof.Size(), "Damaged tail should be cut off")
+
+	restored.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	aof.Close()
+	restored = datastore.NewDataStore()
+	aof, _ = OpenAppendOnlyLog(restored, path, FsyncAlways)
+	defer aof.Close()
+	assert.Equal(t, 2, restored.Count(), "Log should be appendable after truncation")
+}
+
+func TestAppendOnlyLog_badFile(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	ioutil.WriteFile(path, []byte("garbage"), 0644)
+
+	_, err := OpenAppendOnlyLog(datastore.NewDataStore(), path, FsyncAlways)
+	assert.NotNil(t, err)
+}
+
+func TestAppendOnlyLog_Rewrite(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, _ := OpenAppendOnlyLog(storage, path, FsyncEverySecond)
+	for i := 0; i < 100; i++ {
+		storage.Set("name", datatype.NewString(fmt.Sprintf("Ivan %d", i), time.Minute))
+	}
+	sizeBefore := aof.Size()
+	assert.Equal(t, true, aof.NeedsRewrite(0, 100))
+
+	assert.Nil(t, aof.Rewrite())
+	assert.Equal(t, true, aof.Size() < sizeBefore/10, "Log should be compacted")
+	assert.Equal(t, false, aof.NeedsRewrite(0, 100))
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	aof.Close()
+
+	restored := datastore.NewDataStore()
+	aof, _ = OpenAppendOnlyLog(restored, path, FsyncEverySecond)
+	defer aof.Close()
+	value, _ := restored.Get("name")
+	assert.Equal(t, "Ivan 99", value.(datatype.DataType).Value)
+	assert.Equal(t, true, restored.Contains("weight"))
+}
+
+func TestAppendOnlyLog_StartAutoRewrite(t *testing.T) {
+	path, cleanup := tempAofPath(t)
+	defer cleanup()
+	storage := datastore.NewDataStore()
+	aof, _ := OpenAppendOnlyLog(storage, path, FsyncNever)
+	defer aof.Close()
+	for i := 0; i < 100; i++ {
+		storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	}
+	sizeBefore := aof.Size()
+
+	aof.StartAutoRewrite(10*time.Millisecond, 0, 100)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, aof.Size() < sizeBefore, "Log should be compacted automatically")
+}
+
+func ExampleOpenAppendOnlyLog() {
+	storage := datastore.NewDataStore()
+	aof, err := OpenAppendOnlyLog(storage, "cache.aof", FsyncEverySecond)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer aof.Close()
+	aof.StartAutoRewrite(10*time.Second, 64*1024*1024, 100)
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+}
+
+func BenchmarkAppendOnlyLog_append(b *testing.B) {
+	dir, _ := ioutil.TempDir("", "go-cache")
+	defer os.RemoveAll(dir)
+	storage := datastore.NewDataStore()
+	aof, _ := OpenAppendOnlyLog(storage, filepath.Join(dir, "cache.aof"), FsyncNever)
+	defer aof.Close()
+
+	for n := 0; n < b.N; n++ {
+		storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	}
+}
@@ -0,0 +1,151 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SnapshotVersion is a version of snapshot file format written by this package.
+const SnapshotVersion uint32 = 1
+
+// snapshotMagic is written at the beginning of each snapshot file.
+var snapshotMagic = [8]byte{'G', 'O', 'C', 'A', 'C', 'H', 'E', 'S'}
+
+var (
+	// ErrBadSnapshot is returned when file is not a snapshot file.
+	ErrBadSnapshot = errors.New("persistence: not a snapshot file")
+	// ErrUnsupportedVersion is returned when snapshot file has unknown format version.
+	ErrUnsupportedVersion = errors.New("persistence: unsupported snapshot version")
+)
+
+func init() {
+	// Types which could be stored inside DataType.Value
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register(map[interface{}]interface{}{})
+	gob.Register(datatype.Set{})
+	gob.Register(&datatype.SortedSet{})
+}
+
+// snapshot is a body of snapshot file which follows the header.
+type snapshot struct {
+	CreateTime time.Time
+	Entries    []datastore.Entry
+}
+
+// WriteSnapshot writes all items of storage to writer in versioned snapshot format.
+func WriteSnapshot(writer io.Writer, storage *datastore.DataStore) error {
+	if _, err := writer.Write(snapshotMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(writer, binary.BigEndian, SnapshotVersion); err != nil {
+		return err
+	}
+	return gob.NewEncoder(writer).Encode(snapshot{time.Now(), storage.Entries()})
+}
+
+// ReadSnapshot reads snapshot from reader and puts its items into storage.
+// Already expired items are skipped. Returns amount of loaded items.
+func ReadSnapshot(reader io.Reader, storage *datastore.DataStore) (int, error) {
+	var magic [8]byte
+	if _, err := io.ReadFull(reader, magic[:]); err != nil || magic != snapshotMagic {
+		return 0, ErrBadSnapshot
+	}
+	var version uint32
+	if err := binary.Read(reader, binary.BigEndian, &version); err != nil {
+		return 0, ErrBadSnapshot
+	}
+	if version != SnapshotVersion {
+		return 0, ErrUnsupportedVersion
+	}
+
+	var body snapshot
+	if err := gob.NewDecoder(reader).Decode(&body); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	count := 0
+	for _, entry := range body.Entries {
+		if !entry.Value.IsExpired(now) {
+			storage.Set(entry.Key, entry.Value)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Snapshotter saves storage content to snapshot file and loads it back.
+// Snapshotter is concurrency-safe.
+type Snapshotter struct {
+	mutex   sync.Mutex
+	storage *datastore.DataStore
+	path    string
+}
+
+// NewSnapshotter creates Snapshotter which uses file with provided path.
+func NewSnapshotter(storage *datastore.DataStore, path string) *Snapshotter {
+	return &Snapshotter{storage: storage, path: path}
+}
+
+// Save writes snapshot of storage into temporary file and then atomically replaces snapshot file with it.
+func (s *Snapshotter) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	if err := WriteSnapshot(writer, s.storage); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.path)
+}
+
+// Load reads snapshot file into storage. Absent snapshot file is not an error.
+// Returns amount of loaded items.
+func (s *Snapshotter) Load() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count, err := ReadSnapshot(bufio.NewReader(file), s.storage)
+	if err != nil {
+		return 0, fmt.Errorf("loading snapshot %s: %w", s.path, err)
+	}
+	return count, nil
+}
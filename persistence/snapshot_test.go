@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteSnapshot_ReadSnapshot(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("phones", datatype.NewList([]interface{}{"Xiaomi", "Samsung"}, 2*time.Minute))
+	storage.Set("cards", datatype.NewDict(map[interface{}]interface{}{2: "Visa", 3: "Maestro"}, 3*time.Minute))
+	storage.Set("marks", datatype.DataType{Value: map[string]interface{}{"Math": 9.0}, Ttl: time.Minute, DeathTime: time.Now().Add(time.Minute)})
+	storage.Set("city", datatype.NewString("Minsk", 0))
+	storage.Set("flags", datatype.NewSet([]string{"dark-mode", "beta"}, time.Minute))
+	var buf bytes.Buffer
+
+	err := WriteSnapshot(&buf, storage)
+	assert.Nil(t, err)
+
+	restored := datastore.NewDataStore()
+	count, err := ReadSnapshot(&buf, restored)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, count)
+	for _, entry := range storage.Entries() {
+		value, ok := restored.Get(entry.Key)
+		assert.Equal(t, true, ok, "Item should be restored: "+entry.Key)
+		item := value.(datatype.DataType)
+		assert.Equal(t, entry.Value.Value, item.Value)
+		assert.Equal(t, entry.Value.Ttl, item.Ttl)
+		assert.Equal(t, true, entry.Value.DeathTime.Equal(item.DeathTime), "DeathTime should be restored")
+	}
+}
+
+func TestWriteSnapshot_ReadSnapshot_sortedSet(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.ZAdd("scores", map[string]float64{"Ivan": 10, "Petr": 7.5})
+	var buf bytes.Buffer
+
+	assert.Nil(t, WriteSnapshot(&buf, storage))
+	restored := datastore.NewDataStore()
+	count, err := ReadSnapshot(&buf, restored)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	members, err := restored.ZRange("scores", 0, -1, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []datatype.ScoredMember{{Member: "Petr", Score: 7.5}, {Member: "Ivan", Score: 10}}, members)
+}
+
+func TestReadSnapshot_skipsExpiredItems(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", -time.Second))
+	var buf bytes.Buffer
+	WriteSnapshot(&buf, storage)
+
+	restored := datastore.NewDataStore()
+	count, err := ReadSnapshot(&buf, restored)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []interface{}{"name"}, restored.GetKeys())
+}
+
+func TestReadSnapshot_badFile(t *testing.T) {
+	_, err := ReadSnapshot(bytes.NewReader([]byte("not a snapshot")), datastore.NewDataStore())
+	assert.Equal(t, ErrBadSnapshot, err)
+
+	_, err = ReadSnapshot(bytes.NewReader([]byte("GOCACHES\x00\x00\x00\x09")), datastore.NewDataStore())
+	assert.Equal(t, ErrUnsupportedVersion, err)
+}
+
+func TestSnapshotter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.snapshot")
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+
+	count, err := NewSnapshotter(storage, path).Load()
+	assert.Nil(t, err, "Absent snapshot file is not an error")
+	assert.Equal(t, 0, count)
+
+	assert.Nil(t, NewSnapshotter(storage, path).Save())
+	files, _ := ioutil.ReadDir(dir)
+	assert.Equal(t, 1, len(files), "Temporary file should be removed")
+
+	restored := datastore.NewDataStore()
+	count, err = NewSnapshotter(restored, path).Load()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, true, restored.Contains("name"))
+}
+
+func TestSnapshotter_Load_badFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("garbage")
+	file.Close()
+
+	_, err = NewSnapshotter(datastore.NewDataStore(), file.Name()).Load()
+	assert.Equal(t, true, errors.Is(err, ErrBadSnapshot))
+}
+
+func ExampleSnapshotter_Save() {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	fmt.Println(NewSnapshotter(storage, "cache.snapshot").Save())
+}
+
+func ExampleSnapshotter_Load() {
+	storage := datastore.NewDataStore()
+	count, err := NewSnapshotter(storage, "cache.snapshot").Load()
+	fmt.Println(count, err)
+}
+
+func BenchmarkWriteSnapshot(b *testing.B) {
+	storage := datastore.NewDataStore()
+	for i := 0; i < 1000; i++ {
+		storage.Set(fmt.Sprintf("key %d", i), datatype.NewString("value", time.Minute))
+	}
+
+	for n := 0; n < b.N; n++ {
+		var buf bytes.Buffer
+		WriteSnapshot(&buf, storage)
+	}
+}
@@ -0,0 +1,141 @@
+// Package pubsub implements publish/subscribe messaging: messages published to channels are delivered
+// to subscribers of these channels and of glob-style patterns matching them.
+package pubsub
+
+import (
+	"github.com/andrei-punko/go-cache/util"
+	"sort"
+	"sync"
+)
+
+// DefaultBufferSize is amount of messages buffered for each subscription by default.
+const DefaultBufferSize = 256
+
+// Message is a message delivered to subscriber. Pattern is not empty when the message is delivered
+// because of pattern subscription.
+type Message struct {
+	Pattern string `json:"pattern,omitempty"`
+	Channel string `json:"channel"`
+	Payload string `json:"payload"`
+}
+
+// Broker delivers published messages to subscriptions. Broker is concurrency-safe.
+type Broker struct {
+	mutex      sync.Mutex
+	bufferSize int
+	channels   map[string]map[*Subscription]bool
+	patterns   map[string]map[*Subscription]bool
+}
+
+// NewBroker creates Broker with subscriptions buffering bufferSize messages (DefaultBufferSize if it is not positive).
+func NewBroker(bufferSize int) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Broker{
+		bufferSize: bufferSize,
+		channels:   make(map[string]map[*Subscription]bool),
+		patterns:   make(map[string]map[*Subscription]bool),
+	}
+}
+
+// Publish delivers the message to subscribers of the channel and of patterns matching it
+// and returns amount of receivers. Publish doesn't wait for subscribers: subscription
+// which buffer is full is closed, so its subscriber knows that messages were lost.
+func (b *Broker) Publish(channel, payload string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	receivers := 0
+	for subscription := range b.channels[channel] {
+		if b.deliver(subscription, Message{Channel: channel, Payload: payload}) {
+			receivers++
+		}
+	}
+	for pattern, subscriptions := range b.patterns {
+		if !util.MatchPattern(pattern, channel) {
+			continue
+		}
+		for subscription := range subscriptions {
+			if b.deliver(subscription, Message{Pattern: pattern, Channel: channel, Payload: payload}) {
+				receivers++
+			}
+		}
+	}
+	return receivers
+}
+
+// Channels returns sorted channels having subscribers which match glob-style pattern.
+func (b *Broker) Channels(pattern string) []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	result := []string{}
+	for channel := range b.channels {
+		if util.MatchPattern(pattern, channel) {
+			result = append(result, channel)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Subscribers returns amount of subscribers of the channel, pattern subscriptions are not counted.
+func (b *Broker) Subscribers(channel string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.channels[channel])
+}
+
+// Subscribe creates subscription without channels, they are added by Subscription.Subscribe and PSubscribe.
+func (b *Broker) Subscribe() *Subscription {
+	return &Subscription{
+		broker:   b,
+		messages: make(chan Message, b.bufferSize),
+		channels: make(map[string]bool),
+		patterns: make(map[string]bool),
+	}
+}
+
+// deliver sends the message to subscription without blocking. Should be called under the broker lock.
+func (b *Broker) deliver(subscription *Subscription, message Message) bool {
+	select {
+	case subscription.messages <- message:
+		return true
+	default:
+		b.close(subscription)
+		return false
+	}
+}
+
+// close removes subscription from all channels and patterns and closes its messages.
+// Should be called under the broker lock.
+func (b *Broker) close(subscription *Subscription) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	for channel := range subscription.channels {
+		remove(b.channels, channel, subscription)
+	}
+	for pattern := range subscription.patterns {
+		remove(b.patterns, pattern, subscription)
+	}
+	subscription.channels = map[string]bool{}
+	subscription.patterns = map[string]bool{}
+	close(subscription.messages)
+}
+
+func add(index map[string]map[*Subscription]bool, name string, subscription *Subscription) {
+	subscriptions, ok := index[name]
+	if !ok {
+		subscriptions = make(map[*Subscription]bool)
+		index[name] = subscriptions
+	}
+	subscriptions[subscription] = true
+}
+
+func remove(index map[string]map[*Subscription]bool, name string, subscription *Subscription) {
+	delete(index[name], subscription)
+	if len(index[name]) == 0 {
+		delete(index, name)
+	}
+}
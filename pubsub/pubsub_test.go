@@ -0,0 +1,130 @@
+package pubsub
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestBroker_Publish(t *testing.T) {
+	broker := NewBroker(0)
+	news := broker.Subscribe()
+	assert.Equal(t, 1, news.Subscribe("news"))
+	all := broker.Subscribe()
+	assert.Equal(t, 2, all.Subscribe("news", "weather"))
+	assert.Equal(t, 3, all.PSubscribe("news.*"))
+
+	assert.Equal(t, 2, broker.Publish("news", "hello"))
+	assert.Equal(t, 1, broker.Publish("news.sport", "goal"))
+	assert.Equal(t, 0, broker.Publish("traffic", "jam"))
+
+	assert.Equal(t, Message{Channel: "news", Payload: "hello"}, <-news.Messages())
+	assert.Equal(t, Message{Channel: "news", Payload: "hello"}, <-all.Messages())
+	assert.Equal(t, Message{Pattern: "news.*", Channel: "news.sport", Payload: "goal"}, <-all.Messages())
+	assert.Equal(t, 0, len(news.Messages()))
+	assert.Equal(t, 0, len(all.Messages()))
+}
+
+func TestBroker_Publish_channelAndPattern(t *testing.T) {
+	broker := NewBroker(0)
+	subscription := broker.Subscribe()
+	subscription.Subscribe("news")
+	subscription.PSubscribe("n*", "*")
+
+	assert.Equal(t, 3, broker.Publish("news", "hello"), "Message is delivered once per matching subscription")
+	assert.Equal(t, 3, len(subscription.Messages()))
+}
+
+func TestSubscription_Unsubscribe(t *testing.T) {
+	broker := NewBroker(0)
+	subscription := broker.Subscribe()
+	subscription.Subscribe("news", "weather", "traffic")
+	subscription.PSubscribe("news.*", "weather.*")
+
+	assert.Equal(t, 4, subscription.Unsubscribe("traffic", "absent"))
+	assert.Equal(t, []string{"news", "weather"}, subscription.Channels())
+	assert.Equal(t, []string{"news", "weather"}, broker.Channels("*"))
+	assert.Equal(t, 2, subscription.Unsubscribe())
+	assert.Equal(t, []string{}, broker.Channels("*"))
+	assert.Equal(t, 1, subscription.PUnsubscribe("news.*"))
+	assert.Equal(t, []string{"weather.*"}, subscription.Patterns())
+	assert.Equal(t, 0, subscription.PUnsubscribe())
+	assert.Equal(t, 0, broker.Publish("weather.minsk", "rain"))
+}
+
+func TestSubscription_Close(t *testing.T) {
+	broker := NewBroker(0)
+	subscription := broker.Subscribe()
+	subscription.Subscribe("news")
+	broker.Publish("news", "hello")
+
+	subscription.Close()
+	subscription.Close()
+	message, ok := <-subscription.Messages()
+	assert.Equal(t, "hello", message.Payload, "Buffered messages are kept")
+	assert.Equal(t, true, ok)
+	_, ok = <-subscription.Messages()
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 0, subscription.Subscribe("weather"), "Closed subscription is not changed")
+	assert.Equal(t, 0, broker.Subscribers("news"))
+	assert.Equal(t, 0, broker.Publish("weather", "rain"))
+}
+
+func TestSubscription_slowSubscriber(t *testing.T) {
+	broker := NewBroker(2)
+	slow := broker.Subscribe()
+	slow.Subscribe("news")
+	fast := broker.Subscribe()
+	fast.Subscribe("news")
+
+	assert.Equal(t, 2, broker.Publish("news", "1"))
+	assert.Equal(t, 2, broker.Publish("news", "2"))
+	<-fast.Messages()
+	<-fast.Messages()
+	assert.Equal(t, 1, broker.Publish("news", "3"), "Subscription with full buffer is closed")
+	assert.Equal(t, 0, slow.Count())
+	assert.Equal(t, 1, broker.Subscribers("news"))
+
+	received := []string{}
+	for message := range slow.Messages() {
+		received = append(received, message.Payload)
+	}
+	assert.Equal(t, []string{"1", "2"}, received)
+}
+
+func TestBroker_concurrent(t *testing.T) {
+	broker := NewBroker(1000)
+	subscription := broker.Subscribe()
+	subscription.PSubscribe("channel:*")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			other := broker.Subscribe()
+			defer other.Close()
+			for j := 0; j < 100; j++ {
+				other.Subscribe(fmt.Sprintf("channel:%d", j))
+				broker.Publish(fmt.Sprintf("channel:%d", i), "message")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, len(subscription.Messages()))
+	assert.Equal(t, []string{}, broker.Channels("*"))
+}
+
+func ExampleBroker_Publish() {
+	broker := NewBroker(0)
+	subscription := broker.Subscribe()
+	defer subscription.Close()
+	subscription.PSubscribe("invalidate:*")
+
+	broker.Publish("invalidate:users", "42")
+	message := <-subscription.Messages()
+	fmt.Println(message.Channel, message.Payload)
+	// Output:
+	// invalidate:users 42
+}
@@ -0,0 +1,104 @@
+package pubsub
+
+import "sort"
+
+// Subscription receives messages of its channels and patterns. Subscription is concurrency-safe.
+type Subscription struct {
+	broker   *Broker
+	messages chan Message
+	channels map[string]bool
+	patterns map[string]bool
+	closed   bool
+}
+
+// Messages returns channel of received messages. It is closed when the subscription is closed
+// by Close or because its subscriber didn't keep up with published messages.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Subscribe adds channels to the subscription and returns amount of its channels and patterns.
+func (s *Subscription) Subscribe(channels ...string) int {
+	return s.change(channels, false, true)
+}
+
+// Unsubscribe removes channels (all channels if none is provided) from the subscription
+// and returns amount of its remaining channels and patterns.
+func (s *Subscription) Unsubscribe(channels ...string) int {
+	return s.change(channels, false, false)
+}
+
+// PSubscribe adds glob-style patterns to the subscription and returns amount of its channels and patterns.
+func (s *Subscription) PSubscribe(patterns ...string) int {
+	return s.change(patterns, true, true)
+}
+
+// PUnsubscribe removes patterns (all patterns if none is provided) from the subscription
+// and returns amount of its remaining channels and patterns.
+func (s *Subscription) PUnsubscribe(patterns ...string) int {
+	return s.change(patterns, true, false)
+}
+
+// Channels returns sorted channels of the subscription.
+func (s *Subscription) Channels() []string {
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+	return sortedNames(s.channels)
+}
+
+// Patterns returns sorted patterns of the subscription.
+func (s *Subscription) Patterns() []string {
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+	return sortedNames(s.patterns)
+}
+
+// Count returns amount of channels and patterns of the subscription.
+func (s *Subscription) Count() int {
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+	return len(s.channels) + len(s.patterns)
+}
+
+// Close removes all channels and patterns of the subscription and closes its messages.
+func (s *Subscription) Close() {
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+	s.broker.close(s)
+}
+
+// change adds channels (patterns if pattern is true) to the subscription if subscribe is true
+// or removes them otherwise. Closed subscription is not changed.
+func (s *Subscription) change(names []string, pattern, subscribe bool) int {
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+	if s.closed {
+		return 0
+	}
+	own, index := s.channels, s.broker.channels
+	if pattern {
+		own, index = s.patterns, s.broker.patterns
+	}
+	if !subscribe && len(names) == 0 {
+		names = sortedNames(own)
+	}
+	for _, name := range names {
+		if subscribe {
+			own[name] = true
+			add(index, name, s)
+		} else if own[name] {
+			delete(own, name)
+			remove(index, name, s)
+		}
+	}
+	return len(s.channels) + len(s.patterns)
+}
+
+func sortedNames(names map[string]bool) []string {
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/gorilla/mux"
+	json "github.com/json-iterator/go"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// heartbeatInterval is an interval between comments sent to idle event stream, so proxies don't close it.
+const heartbeatInterval = 15 * time.Second
+
+// receiversResponse is a body of publish response.
+type receiversResponse struct {
+	Receivers int `json:"receivers"`
+}
+
+// PublishMessage publishes request body to the channel and returns amount of receivers.
+func PublishMessage(writer http.ResponseWriter, request *http.Request) {
+	payload, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+	receivers := Broker.Publish(mux.Vars(request)["channel"], string(payload))
+	writeJson(writer, http.StatusOK, receiversResponse{receivers})
+}
+
+// SubscribeChannels streams messages of channels from "channel" query parameters and of glob-style patterns
+// from "pattern" ones as Server-Sent Events. Each event has "message" type and json-encoded pubsub.Message data.
+// Stream is finished when client disconnects or doesn't keep up with published messages.
+func SubscribeChannels(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	channels, patterns := query["channel"], query["pattern"]
+	flusher, ok := writer.(http.Flusher)
+	if len(channels)+len(patterns) == 0 || !ok {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	subscription := Broker.Subscribe()
+	defer subscription.Close()
+	if len(channels) > 0 {
+		subscription.Subscribe(channels...)
+	}
+	if len(patterns) > 0 {
+		subscription.PSubscribe(patterns...)
+	}
+	startEventStream(writer, flusher)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return
+			}
+			err = writeEvent(writer, "message", message)
+		case <-heartbeat.C:
+			_, err = writer.Write([]byte(": heartbeat\n\n"))
+		case <-request.Context().Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// startEventStream writes headers of Server-Sent Events stream and initial comment,
+// so client knows that subscription is active.
+func startEventStream(writer http.ResponseWriter, flusher http.Flusher) {
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write([]byte(": subscribed\n\n"))
+	flusher.Flush()
+}
+
+// writeEvent writes Server-Sent Event of provided type with json-encoded data, which never contains new lines.
+func writeEvent(writer http.ResponseWriter, eventType string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write([]byte("event: " + eventType + "\ndata: " + string(data) + "\n\n"))
+	return err
+}
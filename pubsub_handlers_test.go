@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPubSubServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/channels", SubscribeChannels).Methods(http.MethodGet)
+	router.HandleFunc("/channels/{channel}", PublishMessage).Methods(http.MethodPost)
+	return httptest.NewServer(router)
+}
+
+// readEvent reads lines of the next event (or comment) of event stream without trailing empty line.
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	lines := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestSubscribeChannels(t *testing.T) {
+	server := newPubSubServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/channels?channel=news&pattern=invalidate:*", "")
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	reader := bufio.NewReader(response.Body)
+	assert.Equal(t, ": subscribed\n", readEvent(t, reader))
+
+	response = doRequest(t, http.MethodPost, server.URL+"/channels/news", "hello\nworld")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.JSONEq(t, `{"receivers": 1}`, readBody(t, response))
+	assert.Equal(t, "event: message\ndata: {\"channel\":\"news\",\"payload\":\"hello\\nworld\"}\n", readEvent(t, reader))
+
+	Broker.Publish("invalidate:users", "42")
+	assert.Equal(t, "event: message\ndata: {\"pattern\":\"invalidate:*\",\"channel\":\"invalidate:users\",\"payload\":\"42\"}\n", readEvent(t, reader))
+
+	response = doRequest(t, http.MethodPost, server.URL+"/channels/weather", "rain")
+	assert.JSONEq(t, `{"receivers": 0}`, readBody(t, response))
+}
+
+func TestSubscribeChannels_disconnect(t *testing.T) {
+	server := newPubSubServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/channels?channel=disconnect", "")
+	readEvent(t, bufio.NewReader(response.Body))
+	assert.Equal(t, 1, Broker.Subscribers("disconnect"))
+	response.Body.Close()
+
+	for i := 0; i < 100 && Broker.Subscribers("disconnect") > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, Broker.Subscribers("disconnect"), "Subscription is closed when client disconnects")
+}
+
+func TestSubscribeChannels_withoutChannels(t *testing.T) {
+	server := newPubSubServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/channels", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
@@ -0,0 +1,132 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/command"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxBulkLength limits size of a single bulk string received from client.
+const maxBulkLength = 512 * 1024 * 1024
+
+// maxArrayLength limits amount of arguments of a single command received from client.
+const maxArrayLength = 1024 * 1024
+
+// maxLineLength limits size of inline command or header line received from client, as in Redis.
+const maxLineLength = 64 * 1024
+
+var errProtocol = errors.New("ERR Protocol error")
+
+// readCommand reads one command from client: either RESP array of bulk strings or inline command.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return []string{}, nil
+	}
+	if line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 || count > maxArrayLength {
+		return nil, errProtocol
+	}
+	// memory is not preallocated by lengths received from client, it grows with data actually read
+	var args []string
+	for i := 0; i < count; i++ {
+		arg, err := readBulkString(reader)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func readBulkString(reader *bufio.Reader) (string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return "", err
+	}
+	if len(line) == 0 || line[0] != '$' {
+		return "", errProtocol
+	}
+	length, err := strconv.Atoi(line[1:])
+	if err != nil || length < 0 || length > maxBulkLength {
+		return "", errProtocol
+	}
+
+	var builder strings.Builder
+	if _, err := io.CopyN(&builder, reader, int64(length)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	terminator, err := readLine(reader)
+	if err != nil {
+		return "", err
+	}
+	if terminator != "" {
+		return "", errProtocol
+	}
+	return builder.String(), nil
+}
+
+// readLine reads line terminated by CRLF (or LF for inline commands) and strips terminator.
+// Line longer than maxLineLength is a protocol error, so client can't grow memory without sending terminator.
+func readLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(bytes.TrimRight(line, "\r\n")) > maxLineLength {
+			return "", errProtocol
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.TrimRight(line, "\r\n")), nil
+	}
+}
+
+// writeReply encodes reply using RESP2 types and writes it.
+func writeReply(writer *bufio.Writer, reply command.Reply) {
+	switch r := reply.(type) {
+	case nil:
+		writer.WriteString("$-1\r\n")
+	case command.Status:
+		fmt.Fprintf(writer, "+%s\r\n", r)
+	case error:
+		fmt.Fprintf(writer, "-%s\r\n", r.Error())
+	case int:
+		fmt.Fprintf(writer, ":%d\r\n", r)
+	case int64:
+		fmt.Fprintf(writer, ":%d\r\n", r)
+	case string:
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(r), r)
+	case []string:
+		fmt.Fprintf(writer, "*%d\r\n", len(r))
+		for _, item := range r {
+			writeReply(writer, item)
+		}
+	case []interface{}:
+		fmt.Fprintf(writer, "*%d\r\n", len(r))
+		for _, item := range r {
+			writeReply(writer, item)
+		}
+	default:
+		writeReply(writer, fmt.Sprint(r))
+	}
+}
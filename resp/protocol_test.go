@@ -0,0 +1,90 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadCommand(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("*3\r\n$3\r\nSET\r\n$4\r\nname\r\n$9\r\nIvan Ivan\r\n"))
+
+	args, err := readCommand(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SET", "name", "Ivan Ivan"}, args)
+}
+
+func TestReadCommand_inline(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("GET  name\r\n"))
+
+	args, err := readCommand(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GET", "name"}, args)
+}
+
+func TestReadCommand_protocolError(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("*1\r\n:5\r\n"))
+
+	_, err := readCommand(reader)
+	assert.Equal(t, errProtocol, err)
+}
+
+func TestReadCommand_invalidLengths(t *testing.T) {
+	for _, input := range []string{
+		"*-1\r\n",
+		"*1048577\r\n",
+		"*2000000000\r\n$3\r\nGET\r\n",
+		"*1\r\n$-1\r\n",
+		"*1\r\n$536870913\r\n",
+		"*1\r\n$3\r\nGETX\r\n",
+	} {
+		_, err := readCommand(bufio.NewReader(strings.NewReader(input)))
+		assert.Equal(t, errProtocol, err, input)
+	}
+}
+
+func TestReadCommand_truncatedBulk(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("*1\r\n$1000000\r\nGET\r\n"))
+
+	_, err := readCommand(reader)
+	assert.Equal(t, io.ErrUnexpectedEOF, err, "Declared length should not be allocated before data is read")
+}
+
+func TestReadCommand_longLine(t *testing.T) {
+	_, err := readCommand(bufio.NewReader(strings.NewReader(strings.Repeat("a", maxLineLength+1))))
+	assert.Equal(t, errProtocol, err, "Line without terminator should be limited")
+	_, err = readCommand(bufio.NewReader(strings.NewReader("*1\r\n$" + strings.Repeat("1", maxLineLength))))
+	assert.Equal(t, errProtocol, err, "Header line should be limited")
+
+	args, err := readCommand(bufio.NewReader(strings.NewReader("GET " + strings.Repeat("a", maxLineLength-4) + "\r\n")))
+	assert.Nil(t, err)
+	assert.Equal(t, maxLineLength-4, len(args[1]))
+}
+
+func TestWriteReply(t *testing.T) {
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+
+	writeReply(writer, command.StatusOK)
+	writeReply(writer, errors.New("ERR oops"))
+	writeReply(writer, 5)
+	writeReply(writer, "Ivan")
+	writeReply(writer, nil)
+	writeReply(writer, []string{"a", "b"})
+	writer.Flush()
+
+	assert.Equal(t, "+OK\r\n-ERR oops\r\n:5\r\n$4\r\nIvan\r\n$-1\r\n*2\r\n$1\r\na\r\n$1\r\nb\r\n", buf.String())
+}
+
+func BenchmarkReadCommand(b *testing.B) {
+	input := "*3\r\n$3\r\nSET\r\n$4\r\nname\r\n$4\r\nIvan\r\n"
+
+	for n := 0; n < b.N; n++ {
+		readCommand(bufio.NewReader(strings.NewReader(input)))
+	}
+}
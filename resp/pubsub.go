@@ -0,0 +1,135 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/andrei-punko/go-cache/pubsub"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// messageWriteTimeout limits time of writing published message to subscribed client.
+const messageWriteTimeout = 10 * time.Second
+
+// session is a state of client connection. Replies are written both by connection goroutine
+// and by goroutine forwarding messages of subscription, so writer is protected by mutex.
+type session struct {
+	mutex        sync.Mutex
+	writer       *bufio.Writer
+	subscription *pubsub.Subscription
+}
+
+// write writes replies and flushes them to the client if flush is true.
+func (s *session) write(flush bool, replies ...command.Reply) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, reply := range replies {
+		writeReply(s.writer, reply)
+	}
+	if !flush {
+		return nil
+	}
+	return s.writer.Flush()
+}
+
+// isSubscribed returns flag that the session has subscribed channels or patterns.
+func (s *session) isSubscribed() bool {
+	return s.subscription != nil && s.subscription.Count() > 0
+}
+
+func (s *session) close() {
+	if s.subscription != nil {
+		s.subscription.Close()
+	}
+}
+
+// forward writes messages of subscription to the client. Connection is closed when subscription is closed
+// or message is not written during messageWriteTimeout, so client which didn't keep up
+// with published messages is disconnected like Redis does.
+func (s *session) forward(conn net.Conn, subscription *pubsub.Subscription) {
+	defer conn.Close()
+	for message := range subscription.Messages() {
+		reply := []interface{}{"message", message.Channel, message.Payload}
+		if message.Pattern != "" {
+			reply = []interface{}{"pmessage", message.Pattern, message.Channel, message.Payload}
+		}
+		if err := s.writeMessage(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *session) writeMessage(conn net.Conn, reply command.Reply) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(messageWriteTimeout))
+	defer conn.SetWriteDeadline(time.Time{})
+	writeReply(s.writer, reply)
+	return s.writer.Flush()
+}
+
+// subscribe executes SUBSCRIBE, PSUBSCRIBE, UNSUBSCRIBE and PUNSUBSCRIBE commands.
+// Each channel or pattern is confirmed by separate reply with amount of active subscriptions of the session.
+func (s *Server) subscribe(session *session, conn net.Conn, name string, names []string) []command.Reply {
+	broker := s.executor.Broker()
+	if broker == nil {
+		return []command.Reply{errors.New("ERR publish/subscribe is disabled")}
+	}
+	isSubscribe := name == "SUBSCRIBE" || name == "PSUBSCRIBE"
+	if isSubscribe && len(names) == 0 {
+		return []command.Reply{fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))}
+	}
+	kind := strings.ToLower(name)
+	subscription := session.subscription
+	if subscription == nil {
+		if !isSubscribe {
+			return unsubscribeReplies(kind, names)
+		}
+		subscription = broker.Subscribe()
+		session.subscription = subscription
+		go session.forward(conn, subscription)
+	}
+
+	if !isSubscribe && len(names) == 0 {
+		if name == "UNSUBSCRIBE" {
+			names = subscription.Channels()
+		} else {
+			names = subscription.Patterns()
+		}
+		if len(names) == 0 {
+			return []command.Reply{[]interface{}{kind, nil, subscription.Count()}}
+		}
+	}
+	replies := make([]command.Reply, 0, len(names))
+	for _, channel := range names {
+		var count int
+		switch name {
+		case "SUBSCRIBE":
+			count = subscription.Subscribe(channel)
+		case "PSUBSCRIBE":
+			count = subscription.PSubscribe(channel)
+		case "UNSUBSCRIBE":
+			count = subscription.Unsubscribe(channel)
+		default:
+			count = subscription.PUnsubscribe(channel)
+		}
+		replies = append(replies, []interface{}{kind, channel, count})
+	}
+	return replies
+}
+
+// unsubscribeReplies returns replies to unsubscribe command of the session without subscriptions.
+func unsubscribeReplies(kind string, names []string) []command.Reply {
+	if len(names) == 0 {
+		return []command.Reply{[]interface{}{kind, nil, 0}}
+	}
+	replies := make([]command.Reply, 0, len(names))
+	for _, channel := range names {
+		replies = append(replies, []interface{}{kind, channel, 0})
+	}
+	return replies
+}
@@ -0,0 +1,151 @@
+package resp
+
+import (
+	"bufio"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/pubsub"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// startPubSubServer starts Server with publish/subscribe enabled on random local port and returns its address.
+func startPubSubServer(t *testing.T, broker *pubsub.Broker) (*Server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := command.NewExecutor(datastore.NewDataStore(), time.Minute)
+	executor.SetBroker(broker)
+	server := NewServer(executor)
+	go server.Serve(listener)
+	return server, listener.Addr().String()
+}
+
+// client is a connection with single reader, so messages pushed by server are not lost between reads.
+type client struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *client {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &client{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *client) send(request string) {
+	if _, err := c.conn.Write([]byte(request)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) read(lines int) string {
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	result := ""
+	for i := 0; i < lines; i++ {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		result += line
+	}
+	return result
+}
+
+func TestServer_subscribe(t *testing.T) {
+	broker := pubsub.NewBroker(pubsub.DefaultBufferSize)
+	server, addr := startPubSubServer(t, broker)
+	defer server.Close()
+	subscriber := dial(t, addr)
+	publisher := dial(t, addr)
+
+	subscriber.send("SUBSCRIBE news weather\r\n")
+	assert.Equal(t, "*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n*3\r\n$9\r\nsubscribe\r\n$7\r\nweather\r\n:2\r\n", subscriber.read(12))
+	subscriber.send("PSUBSCRIBE news.*\r\n")
+	assert.Equal(t, "*3\r\n$10\r\npsubscribe\r\n$6\r\nnews.*\r\n:3\r\n", subscriber.read(6))
+
+	publisher.send("PUBLISH news hello\r\n")
+	assert.Equal(t, ":1\r\n", publisher.read(1))
+	assert.Equal(t, "*3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n", subscriber.read(7))
+	publisher.send("PUBLISH news.sport goal\r\n")
+	assert.Equal(t, ":1\r\n", publisher.read(1))
+	assert.Equal(t, "*4\r\n$8\r\npmessage\r\n$6\r\nnews.*\r\n$10\r\nnews.sport\r\n$4\r\ngoal\r\n", subscriber.read(9))
+
+	subscriber.send("GET name\r\n")
+	assert.Equal(t, "-ERR Can't execute 'get': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context\r\n", subscriber.read(1))
+	subscriber.send("PING\r\n")
+	assert.Equal(t, "*2\r\n$4\r\npong\r\n$0\r\n\r\n", subscriber.read(5))
+
+	subscriber.send("UNSUBSCRIBE\r\n")
+	assert.Equal(t, "*3\r\n$11\r\nunsubscribe\r\n$4\r\nnews\r\n:2\r\n*3\r\n$11\r\nunsubscribe\r\n$7\r\nweather\r\n:1\r\n", subscriber.read(12))
+	subscriber.send("PUNSUBSCRIBE\r\n")
+	assert.Equal(t, "*3\r\n$12\r\npunsubscribe\r\n$6\r\nnews.*\r\n:0\r\n", subscriber.read(6))
+	subscriber.send("GET name\r\n")
+	assert.Equal(t, "$-1\r\n", subscriber.read(1), "Commands are allowed after unsubscribe from everything")
+	publisher.send("PUBLISH news hello\r\n")
+	assert.Equal(t, ":0\r\n", publisher.read(1))
+}
+
+func TestServer_unsubscribeWithoutSubscriptions(t *testing.T) {
+	server, addr := startPubSubServer(t, pubsub.NewBroker(pubsub.DefaultBufferSize))
+	defer server.Close()
+	client := dial(t, addr)
+
+	client.send("UNSUBSCRIBE\r\n")
+	assert.Equal(t, "*3\r\n$11\r\nunsubscribe\r\n$-1\r\n:0\r\n", client.read(5))
+	client.send("PUNSUBSCRIBE news.*\r\n")
+	assert.Equal(t, "*3\r\n$12\r\npunsubscribe\r\n$6\r\nnews.*\r\n:0\r\n", client.read(6))
+	client.send("SUBSCRIBE\r\n")
+	assert.Equal(t, "-ERR wrong number of arguments for 'subscribe' command\r\n", client.read(1))
+}
+
+func TestServer_subscriberDisconnect(t *testing.T) {
+	broker := pubsub.NewBroker(pubsub.DefaultBufferSize)
+	server, addr := startPubSubServer(t, broker)
+	defer server.Close()
+	subscriber := dial(t, addr)
+
+	subscriber.send("SUBSCRIBE news\r\n")
+	subscriber.read(6)
+	assert.Equal(t, 1, broker.Subscribers("news"))
+	subscriber.conn.Close()
+	for i := 0; i < 100 && broker.Subscribers("news") > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, broker.Subscribers("news"), "Subscription is closed with connection")
+}
+
+func TestServer_slowSubscriber(t *testing.T) {
+	broker := pubsub.NewBroker(1)
+	server, addr := startPubSubServer(t, broker)
+	defer server.Close()
+	subscriber := dial(t, addr)
+	subscriber.send("SUBSCRIBE news\r\n")
+	subscriber.read(6)
+
+	// subscriber doesn't read messages, so its buffers are filled up and it is disconnected
+	payload := string(make([]byte, 64*1024))
+	for i := 0; i < 1000 && broker.Subscribers("news") > 0; i++ {
+		broker.Publish("news", payload)
+	}
+	assert.Equal(t, 0, broker.Subscribers("news"))
+
+	subscriber.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err := ioutil.ReadAll(subscriber.reader)
+	assert.Nil(t, err, "Connection is closed after buffered messages are written")
+}
+
+func TestServer_pubSubDisabled(t *testing.T) {
+	server, conn := startServer(t, datastore.NewDataStore())
+	defer server.Close()
+
+	assert.Equal(t, "-ERR publish/subscribe is disabled\r\n", roundTrip(t, conn, "SUBSCRIBE news\r\n", 1))
+	assert.Equal(t, "-ERR publish/subscribe is disabled\r\n", roundTrip(t, conn, "PUBLISH news hello\r\n", 1))
+}
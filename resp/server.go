@@ -0,0 +1,74 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/andrei-punko/go-cache/tcpserver"
+	"io"
+	"log"
+	"net"
+	"strings"
+)
+
+// Server serves Redis RESP2 protocol using shared command Executor.
+type Server struct {
+	*tcpserver.Server
+	executor *command.Executor
+}
+
+// NewServer creates Server which executes commands using provided executor.
+func NewServer(executor *command.Executor) *Server {
+	s := &Server{executor: executor}
+	s.Server = tcpserver.NewServer(s.serveConn)
+	return s
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	session := &session{writer: bufio.NewWriter(conn)}
+	defer session.close()
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			if err == errProtocol {
+				session.write(true, err)
+			} else if err != io.EOF {
+				log.Printf("RESP connection error: %v", err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		if strings.ToUpper(args[0]) == "QUIT" {
+			session.write(true, command.StatusOK)
+			return
+		}
+		if err := session.write(reader.Buffered() == 0, s.execute(session, conn, args)...); err != nil {
+			return
+		}
+	}
+}
+
+// execute runs command in context of the session and returns its replies.
+// Only publish/subscribe commands produce several replies.
+func (s *Server) execute(session *session, conn net.Conn, args []string) []command.Reply {
+	name := strings.ToUpper(args[0])
+	switch name {
+	case "SUBSCRIBE", "PSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE":
+		return s.subscribe(session, conn, name, args[1:])
+	}
+	if session.isSubscribed() {
+		if name != "PING" {
+			return []command.Reply{fmt.Errorf("ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context", strings.ToLower(name))}
+		}
+		message := ""
+		if len(args) > 1 {
+			message = args[1]
+		}
+		return []command.Reply{[]interface{}{"pong", message}}
+	}
+	return []command.Reply{s.executor.Execute(args)}
+}
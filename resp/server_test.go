@@ -0,0 +1,144 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+// startServer starts Server on random local port and returns connection to it.
+func startServer(t *testing.T, storage *datastore.DataStore) (*Server, net.Conn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(command.NewExecutor(storage, time.Minute))
+	go server.Serve(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, conn
+}
+
+// roundTrip sends raw request and reads expected amount of reply lines.
+func roundTrip(t *testing.T, conn net.Conn, request string, lines int) string {
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	reader := bufio.NewReader(conn)
+	result := ""
+	for i := 0; i < lines; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		result += line
+	}
+	return result
+}
+
+func TestServer_setAndGet(t *testing.T) {
+	storage := datastore.NewDataStore()
+	server, conn := startServer(t, storage)
+	defer server.Close()
+
+	assert.Equal(t, "+OK\r\n", roundTrip(t, conn, "*5\r\n$3\r\nSET\r\n$4\r\nname\r\n$4\r\nIvan\r\n$2\r\nEX\r\n$2\r\n60\r\n", 1))
+	assert.Equal(t, "$4\r\nIvan\r\n", roundTrip(t, conn, "GET name\r\n", 2))
+	assert.Equal(t, "$-1\r\n", roundTrip(t, conn, "GET absent\r\n", 1))
+	assert.Equal(t, ":60\r\n", roundTrip(t, conn, "TTL name\r\n", 1))
+	assert.Equal(t, ":-2\r\n", roundTrip(t, conn, "TTL absent\r\n", 1))
+
+	value, ok := storage.Get("name")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "Ivan", value.(datatype.DataType).Value)
+	assert.Equal(t, time.Minute, value.(datatype.DataType).Ttl)
+}
+
+func TestServer_setWithPx(t *testing.T) {
+	storage := datastore.NewDataStore()
+	server, conn := startServer(t, storage)
+	defer server.Close()
+
+	assert.Equal(t, "+OK\r\n", roundTrip(t, conn, "SET name Ivan PX 1500\r\n", 1))
+	value, _ := storage.Get("name")
+	assert.Equal(t, 1500*time.Millisecond, value.(datatype.DataType).Ttl)
+
+	assert.Equal(t, "-ERR syntax error\r\n", roundTrip(t, conn, "SET name Ivan ZZ 5\r\n", 1))
+	assert.Equal(t, "-ERR value is not an integer or out of range\r\n", roundTrip(t, conn, "SET name Ivan EX five\r\n", 1))
+}
+
+func TestServer_getWrongType(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("cards", datatype.NewList([]interface{}{"VISA"}, time.Minute))
+	server, conn := startServer(t, storage)
+	defer server.Close()
+
+	assert.Equal(t, "-"+command.ErrWrongType.Error()+"\r\n", roundTrip(t, conn, "GET cards\r\n", 1))
+}
+
+func TestServer_delAndExists(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	server, conn := startServer(t, storage)
+	defer server.Close()
+
+	assert.Equal(t, ":2\r\n", roundTrip(t, conn, "EXISTS name weight age\r\n", 1))
+	assert.Equal(t, ":1\r\n", roundTrip(t, conn, "DEL name age\r\n", 1))
+	assert.Equal(t, ":1\r\n", roundTrip(t, conn, "EXISTS name weight\r\n", 1))
+}
+
+func TestServer_keys(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	storage.Set("weight", datatype.NewString("82.5kg", 2*time.Minute))
+	server, conn := startServer(t, storage)
+	defer server.Close()
+
+	assert.Equal(t, "*1\r\n$4\r\nname\r\n", roundTrip(t, conn, "KEYS n*\r\n", 3))
+	assert.Equal(t, "*2\r\n$4\r\nname\r\n$6\r\nweight\r\n", roundTrip(t, conn, "KEYS *\r\n", 5))
+}
+
+func TestServer_flushAll(t *testing.T) {
+	storage := datastore.NewDataStore()
+	storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server, conn := startServer(t, storage)
+	defer server.Close()
+
+	assert.Equal(t, "+OK\r\n", roundTrip(t, conn, "FLUSHALL\r\n", 1))
+	assert.Equal(t, 0, storage.Count())
+}
+
+func TestServer_errors(t *testing.T) {
+	server, conn := startServer(t, datastore.NewDataStore())
+	defer server.Close()
+
+	assert.Equal(t, "-ERR unknown command 'FOO'\r\n", roundTrip(t, conn, "FOO\r\n", 1))
+	assert.Equal(t, "-ERR wrong number of arguments for 'get' command\r\n", roundTrip(t, conn, "GET\r\n", 1))
+	assert.Equal(t, "+PONG\r\n", roundTrip(t, conn, "PING\r\n", 1))
+}
+
+func TestServer_Close(t *testing.T) {
+	server, conn := startServer(t, datastore.NewDataStore())
+	assert.Equal(t, "+PONG\r\n", roundTrip(t, conn, "PING\r\n", 1))
+
+	server.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NotNil(t, err, "Connection should be closed")
+}
+
+func ExampleNewServer() {
+	executor := command.NewExecutor(datastore.NewDataStore(), time.Hour)
+	server := NewServer(executor)
+	fmt.Println(server.ListenAndServe(":6379"))
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"github.com/gorilla/mux"
+	json "github.com/json-iterator/go"
+	"net/http"
+	"strconv"
+)
+
+// addedResponse is a body of responses which return amount of added members.
+type addedResponse struct {
+	Added int `json:"added"`
+}
+
+// ReadMembers returns sorted members of the set, or only amount of members when "count" query parameter is true.
+func ReadMembers(writer http.ResponseWriter, request *http.Request) {
+	key := mux.Vars(request)["key"]
+	if countOnly, _ := strconv.ParseBool(request.URL.Query().Get("count")); countOnly {
+		count, err := Commands.SCard(key)
+		if err != nil {
+			populateResponseWriter(writer, storageErrorStatus(err))
+			return
+		}
+		writeJson(writer, http.StatusOK, lengthResponse{count})
+		return
+	}
+
+	members, err := Commands.SMembers(key)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, members)
+}
+
+// AddMembers adds members from json array of strings in request body to the set and returns amount of added members.
+func AddMembers(writer http.ResponseWriter, request *http.Request) {
+	var members []string
+	if err := json.NewDecoder(request.Body).Decode(&members); err != nil || len(members) == 0 {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	added, err := Commands.SAdd(mux.Vars(request)["key"], members...)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, addedResponse{added})
+}
+
+// CheckMember responds with 200 status code when the member is present in the set and with 404 otherwise.
+func CheckMember(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	isMember, err := Commands.SIsMember(vars["key"], vars["member"])
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case !isMember:
+		populateResponseWriter(writer, http.StatusNotFound)
+	default:
+		populateResponseWriter(writer, http.StatusOK)
+	}
+}
+
+// AddMember adds the member to the set.
+// Responds with 201 status code when the member is added and with 204 when it is already present.
+func AddMember(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	added, err := Commands.SAdd(vars["key"], vars["member"])
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case added > 0:
+		populateResponseWriter(writer, http.StatusCreated)
+	default:
+		populateResponseWriter(writer, http.StatusNoContent)
+	}
+}
+
+// DeleteMember removes the member from the set.
+func DeleteMember(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	removed, err := Commands.SRem(vars["key"], vars["member"])
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case removed == 0:
+		populateResponseWriter(writer, http.StatusNotFound)
+	default:
+		populateResponseWriter(writer, http.StatusNoContent)
+	}
+}
+
+// CombineSets returns sorted members of union, intersection or difference of the sets listed in "keys" query parameters.
+func CombineSets(writer http.ResponseWriter, request *http.Request) {
+	keys := request.URL.Query()["keys"]
+	if len(keys) == 0 {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	combine := Commands.SUnion
+	switch mux.Vars(request)["operation"] {
+	case "inter":
+		combine = Commands.SInter
+	case "diff":
+		combine = Commands.SDiff
+	}
+	members, err := combine(keys...)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, members)
+}
+
+// StoreCombinedSets saves union, intersection or difference of the sets listed in "keys" query parameters
+// under the key from "store" query parameter and returns amount of its members.
+func StoreCombinedSets(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	keys, destination := query["keys"], query.Get("store")
+	if len(keys) == 0 || destination == "" {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	store := Commands.SUnionStore
+	switch mux.Vars(request)["operation"] {
+	case "inter":
+		store = Commands.SInterStore
+	case "diff":
+		store = Commands.SDiffStore
+	}
+	count, err := store(destination, keys...)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, lengthResponse{count})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSetServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", ReadItem).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/members", ReadMembers).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/members", AddMembers).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/members/{member}", CheckMember).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/items/{key}/members/{member}", AddMember).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/members/{member}", DeleteMember).Methods(http.MethodDelete)
+	router.HandleFunc("/sets/{operation:union|inter|diff}", CombineSets).Methods(http.MethodGet)
+	router.HandleFunc("/sets/{operation:union|inter|diff}", StoreCombinedSets).Methods(http.MethodPost)
+	return httptest.NewServer(router)
+}
+
+func TestAddMembers_ReadMembers(t *testing.T) {
+	Storage.Clear()
+	server := newSetServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/flags/members", `["dark-mode", "beta", "beta"]`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"added":2}`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/flags/members", `[1]`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/flags/members", "")
+	assert.Equal(t, `["beta","dark-mode"]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/flags/members?count=true", "")
+	assert.Equal(t, `{"length":2}`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/flags", "")
+	assert.Contains(t, readBody(t, response), `"value":["beta","dark-mode"]`, "Set is encoded as array")
+}
+
+func TestAddMember_CheckMember_DeleteMember(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server := newSetServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPut, server.URL+"/items/flags/members/beta", "")
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/flags/members/beta", "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodHead, server.URL+"/items/flags/members/beta", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response = doRequest(t, http.MethodGet, server.URL+"/items/flags/members/alpha", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/flags/members/beta", "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/flags/members/beta", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/name/members/beta", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func TestCombineSets_StoreCombinedSets(t *testing.T) {
+	Storage.Clear()
+	Storage.SAdd("ivan", "dark-mode", "beta")
+	Storage.SAdd("petr", "beta", "export")
+	server := newSetServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/sets/union?keys=ivan&keys=petr", "")
+	assert.Equal(t, `["beta","dark-mode","export"]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/sets/inter?keys=ivan&keys=petr", "")
+	assert.Equal(t, `["beta"]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/sets/diff?keys=ivan&keys=petr", "")
+	assert.Equal(t, `["dark-mode"]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/sets/union", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = doRequest(t, http.MethodPost, server.URL+"/sets/inter?keys=ivan&keys=petr&store=common", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"length":1}`, readBody(t, response))
+	members, _ := Storage.SMembers("common")
+	assert.Equal(t, []string{"beta"}, members)
+	response = doRequest(t, http.MethodPost, server.URL+"/sets/inter?keys=ivan&keys=petr", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	json "github.com/json-iterator/go"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// rankResponse is a body of responses which return rank of sorted set member.
+type rankResponse struct {
+	Rank int `json:"rank"`
+}
+
+// ReadScores returns members of the sorted set with their scores ordered by score ascending
+// (descending when "rev" query parameter is true).
+// Members are selected by "min" and "max" scores (with "offset" and "limit") when any of them is present,
+// otherwise by "start" and "stop" positions inclusive (whole sorted set by default).
+// Only amount of members is returned when "count" query parameter is true.
+func ReadScores(writer http.ResponseWriter, request *http.Request) {
+	key := mux.Vars(request)["key"]
+	query := request.URL.Query()
+	if countOnly, _ := strconv.ParseBool(query.Get("count")); countOnly {
+		count, err := Commands.ZCard(key)
+		if err != nil {
+			populateResponseWriter(writer, storageErrorStatus(err))
+			return
+		}
+		writeJson(writer, http.StatusOK, lengthResponse{count})
+		return
+	}
+
+	reversed, _ := strconv.ParseBool(query.Get("rev"))
+	var members []datatype.ScoredMember
+	var err error
+	if query.Get("min") != "" || query.Get("max") != "" {
+		scoreRange, offset, count, ok := parseScoreRangeParams(request)
+		if !ok {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+		members, err = Commands.ZRangeByScore(key, scoreRange, reversed, offset, count)
+	} else {
+		start, stop, ok := parseRangeParams(request)
+		if !ok {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+		members, err = Commands.ZRange(key, start, stop, reversed)
+	}
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, members)
+}
+
+// AddScores sets scores of the members from json object in request body and returns amount of added members.
+func AddScores(writer http.ResponseWriter, request *http.Request) {
+	var members map[string]float64
+	if err := json.NewDecoder(request.Body).Decode(&members); err != nil || len(members) == 0 {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	added, err := Commands.ZAdd(mux.Vars(request)["key"], members)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, addedResponse{added})
+}
+
+// ReadScore returns score of the sorted set member.
+func ReadScore(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	score, ok, err := Commands.ZScore(vars["key"], vars["member"])
+	writeListValue(writer, score, ok, err)
+}
+
+// UpdateScore sets score from request body to the sorted set member.
+// Responds with 201 status code when the member is added and with 204 when score of existing member is updated.
+func UpdateScore(writer http.ResponseWriter, request *http.Request) {
+	var score float64
+	if err := json.NewDecoder(request.Body).Decode(&score); err != nil {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(request)
+	added, err := Commands.ZAdd(vars["key"], map[string]float64{vars["member"]: score})
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case added > 0:
+		populateResponseWriter(writer, http.StatusCreated)
+	default:
+		populateResponseWriter(writer, http.StatusNoContent)
+	}
+}
+
+// DeleteScore removes the member from the sorted set.
+func DeleteScore(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	removed, err := Commands.ZRem(vars["key"], vars["member"])
+	switch {
+	case err != nil:
+		populateResponseWriter(writer, storageErrorStatus(err))
+	case removed == 0:
+		populateResponseWriter(writer, http.StatusNotFound)
+	default:
+		populateResponseWriter(writer, http.StatusNoContent)
+	}
+}
+
+// IncrementScore increments score of the sorted set member by "by" query parameter (1 by default)
+// and returns new score.
+func IncrementScore(writer http.ResponseWriter, request *http.Request) {
+	delta := 1.0
+	if value := request.URL.Query().Get("by"); value != "" {
+		var err error
+		if delta, err = strconv.ParseFloat(value, 64); err != nil {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+	}
+
+	vars := mux.Vars(request)
+	score, err := Commands.ZIncrBy(vars["key"], vars["member"], delta)
+	if err != nil {
+		populateResponseWriter(writer, storageErrorStatus(err))
+		return
+	}
+	writeJson(writer, http.StatusOK, score)
+}
+
+// ReadRank returns position of the sorted set member ordered by score ascending
+// (descending when "rev" query parameter is true), starting from 0.
+func ReadRank(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	reversed, _ := strconv.ParseBool(request.URL.Query().Get("rev"))
+	rank, ok, err := Commands.ZRank(vars["key"], vars["member"], reversed)
+	writeListValue(writer, rankResponse{rank}, ok, err)
+}
+
+// parseScoreRangeParams parses optional "min" and "max" scores (prefixed by "(" for exclusive bound),
+// "offset" and "limit" query parameters. Whole range of scores without limit is used by default.
+func parseScoreRangeParams(request *http.Request) (datatype.ScoreRange, int, int, bool) {
+	scoreRange := datatype.ScoreRange{Min: math.Inf(-1), Max: math.Inf(1)}
+	offset, count := 0, -1
+	query := request.URL.Query()
+	var ok bool
+	if value := query.Get("min"); value != "" {
+		if scoreRange.Min, scoreRange.MinExclusive, ok = parseScoreBound(value); !ok {
+			return scoreRange, 0, 0, false
+		}
+	}
+	if value := query.Get("max"); value != "" {
+		if scoreRange.Max, scoreRange.MaxExclusive, ok = parseScoreBound(value); !ok {
+			return scoreRange, 0, 0, false
+		}
+	}
+	var err error
+	if value := query.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return scoreRange, 0, 0, false
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if count, err = strconv.Atoi(value); err != nil {
+			return scoreRange, 0, 0, false
+		}
+	}
+	return scoreRange, offset, count, true
+}
+
+// parseScoreBound parses bound of score range, exclusive bound is prefixed by "(".
+func parseScoreBound(value string) (float64, bool, bool) {
+	exclusive := len(value) > 0 && value[0] == '('
+	if exclusive {
+		value = value[1:]
+	}
+	score, err := strconv.ParseFloat(value, 64)
+	return score, exclusive, err == nil && !math.IsNaN(score)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSortedSetServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", ReadItem).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/scores", ReadScores).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/scores", AddScores).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/scores/{member}", ReadScore).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/scores/{member}", UpdateScore).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/scores/{member}", DeleteScore).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/scores/{member}/incr", IncrementScore).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/scores/{member}/rank", ReadRank).Methods(http.MethodGet)
+	return httptest.NewServer(router)
+}
+
+func TestAddScores_ReadScores(t *testing.T) {
+	Storage.Clear()
+	server := newSortedSetServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/leaders/scores", `{"ivan": 150, "petr": 90, "olga": 300}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"added":3}`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/leaders/scores", `["ivan"]`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores?start=0&stop=1&rev=true", "")
+	assert.Equal(t, `[{"member":"olga","score":300},{"member":"ivan","score":150}]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores?min=(90&max=300&limit=1", "")
+	assert.Equal(t, `[{"member":"ivan","score":150}]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores?max=150&offset=1", "")
+	assert.Equal(t, `[{"member":"ivan","score":150}]`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores?count=true", "")
+	assert.Equal(t, `{"length":3}`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores?min=low", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders", "")
+	assert.Contains(t, readBody(t, response), `"value":[{"member":"petr","score":90},`)
+}
+
+func TestUpdateScore_ReadScore_DeleteScore(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server := newSortedSetServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPut, server.URL+"/items/leaders/scores/ivan", `150`)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/leaders/scores/ivan", `170.5`)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores/ivan", "")
+	assert.Equal(t, `170.5`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores/petr", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/leaders/scores/ivan", "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/leaders/scores/ivan", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/name/scores/ivan", `150`)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/leaders/scores/ivan", `"many"`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestIncrementScore_ReadRank(t *testing.T) {
+	Storage.Clear()
+	Storage.ZAdd("leaders", map[string]float64{"ivan": 150, "petr": 90})
+	server := newSortedSetServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/items/leaders/scores/petr/incr?by=100", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `190`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/leaders/scores/anna/incr", "")
+	assert.Equal(t, `1`, readBody(t, response))
+	response = doRequest(t, http.MethodPost, server.URL+"/items/leaders/scores/anna/incr?by=much", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores/petr/rank", "")
+	assert.Equal(t, `{"rank":2}`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores/petr/rank?rev=true", "")
+	assert.Equal(t, `{"rank":0}`, readBody(t, response))
+	response = doRequest(t, http.MethodGet, server.URL+"/items/leaders/scores/olga/rank", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
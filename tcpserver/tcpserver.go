@@ -0,0 +1,101 @@
+package tcpserver
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// ErrServerClosed is returned by Serve after a call to Close.
+var ErrServerClosed = errors.New("tcpserver: server closed")
+
+// Server accepts TCP connections and serves each of them in separate goroutine using handler.
+type Server struct {
+	handler func(conn net.Conn)
+
+	mutex     sync.Mutex
+	listener  net.Listener
+	conns     map[net.Conn]struct{}
+	isClosing bool
+}
+
+// NewServer creates Server which passes each accepted connection to provided handler.
+// Connection is closed when handler returns.
+func NewServer(handler func(conn net.Conn)) *Server {
+	return &Server{
+		handler: handler,
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP network address addr and then calls Serve.
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts incoming connections on the listener. It always returns a non-nil error.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	s.listener = listener
+	s.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if s.closing() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		go func() {
+			defer s.untrack(conn)
+			s.handler(conn)
+		}()
+	}
+}
+
+// Close stops listener and closes all active connections.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.isClosing = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *Server) closing() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isClosing
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.isClosing {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mutex.Lock()
+	delete(s.conns, conn)
+	s.mutex.Unlock()
+	conn.Close()
+}
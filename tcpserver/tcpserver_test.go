@@ -0,0 +1,44 @@
+package tcpserver
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(func(conn net.Conn) {
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("echo: " + line))
+	})
+	result := make(chan error)
+	go func() {
+		result <- server.Serve(listener)
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(time.Second))
+	conn.Write([]byte("hello\n"))
+	line, _ := bufio.NewReader(conn).ReadString('\n')
+	assert.Equal(t, "echo: hello\n", line)
+
+	server.Close()
+	assert.Equal(t, ErrServerClosed, <-result)
+}
+
+func ExampleNewServer() {
+	server := NewServer(func(conn net.Conn) {
+		fmt.Fprintln(conn, "Hello!")
+	})
+	fmt.Println(server.ListenAndServe(":2323"))
+}
@@ -0,0 +1,95 @@
+package telnet
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/andrei-punko/go-cache/tcpserver"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Server serves plain-text line protocol, for example "SET name Ivan 60s", using shared command Executor.
+// Replies are formatted similar to redis-cli output.
+type Server struct {
+	*tcpserver.Server
+	executor *command.Executor
+}
+
+// NewServer creates Server which executes commands using provided executor.
+func NewServer(executor *command.Executor) *Server {
+	s := &Server{executor: executor}
+	s.Server = tcpserver.NewServer(s.serveConn)
+	return s
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Telnet connection error: %v", err)
+			}
+			return
+		}
+
+		args, err := command.Parse(line)
+		if err != nil {
+			writer.WriteString(formatReply(err))
+		} else if len(args) == 0 {
+			continue
+		} else if strings.ToUpper(args[0]) == "QUIT" {
+			writer.WriteString(formatReply(command.StatusOK))
+			writer.Flush()
+			return
+		} else {
+			writer.WriteString(formatReply(s.executor.Execute(args)))
+		}
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// formatReply converts reply to human-readable text terminated by CRLF.
+func formatReply(reply command.Reply) string {
+	switch r := reply.(type) {
+	case nil:
+		return "(nil)\r\n"
+	case command.Status:
+		return string(r) + "\r\n"
+	case error:
+		return "(error) " + r.Error() + "\r\n"
+	case int:
+		return fmt.Sprintf("(integer) %d\r\n", r)
+	case int64:
+		return fmt.Sprintf("(integer) %d\r\n", r)
+	case string:
+		return strconv.Quote(r) + "\r\n"
+	case []string:
+		if len(r) == 0 {
+			return "(empty list)\r\n"
+		}
+		var builder strings.Builder
+		for i, item := range r {
+			fmt.Fprintf(&builder, "%d) %s", i+1, formatReply(item))
+		}
+		return builder.String()
+	case []interface{}:
+		if len(r) == 0 {
+			return "(empty list)\r\n"
+		}
+		var builder strings.Builder
+		for i, item := range r {
+			fmt.Fprintf(&builder, "%d) %s", i+1, formatReply(item))
+		}
+		return builder.String()
+	default:
+		return fmt.Sprint(r) + "\r\n"
+	}
+}
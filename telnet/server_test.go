@@ -0,0 +1,74 @@
+package telnet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/andrei-punko/go-cache/command"
+	"github.com/andrei-punko/go-cache/datastore"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	storage := datastore.NewDataStore()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(command.NewExecutor(storage, time.Minute))
+	go server.Serve(listener)
+	defer server.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(time.Second))
+
+	conn.Write([]byte("SET name \"Ivan Ivanov\" 60s\r\n"))
+	line, _ := reader.ReadString('\n')
+	assert.Equal(t, "OK\r\n", line)
+
+	conn.Write([]byte("\r\nget name\n"))
+	line, _ = reader.ReadString('\n')
+	assert.Equal(t, "\"Ivan Ivanov\"\r\n", line)
+
+	conn.Write([]byte("GET \"name\n"))
+	line, _ = reader.ReadString('\n')
+	assert.Equal(t, "(error) ERR unbalanced quotes in request\r\n", line)
+
+	conn.Write([]byte("QUIT\n"))
+	line, _ = reader.ReadString('\n')
+	assert.Equal(t, "OK\r\n", line)
+	_, err = reader.ReadString('\n')
+	assert.NotNil(t, err, "Connection should be closed after QUIT")
+	assert.Equal(t, true, storage.Contains("name"))
+}
+
+func TestFormatReply(t *testing.T) {
+	assert.Equal(t, "(nil)\r\n", formatReply(nil))
+	assert.Equal(t, "OK\r\n", formatReply(command.StatusOK))
+	assert.Equal(t, "(error) ERR oops\r\n", formatReply(errors.New("ERR oops")))
+	assert.Equal(t, "(integer) 2\r\n", formatReply(2))
+	assert.Equal(t, "(integer) 60\r\n", formatReply(int64(60)))
+	assert.Equal(t, "\"Ivan\"\r\n", formatReply("Ivan"))
+	assert.Equal(t, "1) \"name\"\r\n2) \"weight\"\r\n", formatReply([]string{"name", "weight"}))
+	assert.Equal(t, "(empty list)\r\n", formatReply([]string{}))
+}
+
+func ExampleNewServer() {
+	executor := command.NewExecutor(datastore.NewDataStore(), time.Hour)
+	server := NewServer(executor)
+	fmt.Println(server.ListenAndServe(":2323"))
+}
+
+func BenchmarkFormatReply(b *testing.B) {
+	reply := []string{"name", "weight", "age"}
+
+	for n := 0; n < b.N; n++ {
+		formatReply(reply)
+	}
+}
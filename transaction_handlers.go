@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/andrei-punko/go-cache/command"
+	json "github.com/json-iterator/go"
+	"net/http"
+)
+
+// transactionRequest is a body of transaction request: commands in RESP syntax
+// and versions of watched items (zero version means absent item).
+type transactionRequest struct {
+	Watch    map[string]uint64 `json:"watch"`
+	Commands [][]string        `json:"commands"`
+}
+
+// errorResponse is a body of failed command reply or of invalid request response.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ExecuteTransaction executes commands from request body atomically and returns array of their replies,
+// failed commands have {"error": "..."} replies and don't stop the transaction.
+// Responds with 412 status code without executing commands when any watched item was changed
+// and with 400 one when any command is unknown or has wrong amount of arguments.
+func ExecuteTransaction(writer http.ResponseWriter, request *http.Request) {
+	var transaction transactionRequest
+	if err := json.NewDecoder(request.Body).Decode(&transaction); err != nil || len(transaction.Commands) == 0 {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	replies, err := Commands.ExecuteAll(transaction.Watch, transaction.Commands)
+	switch {
+	case err == command.ErrAborted:
+		writeJson(writer, http.StatusPreconditionFailed, errorResponse{err.Error()})
+	case err != nil:
+		writeJson(writer, http.StatusBadRequest, errorResponse{err.Error()})
+	default:
+		result := make([]interface{}, len(replies))
+		for i, reply := range replies {
+			result[i] = jsonReply(reply)
+		}
+		writeJson(writer, http.StatusOK, result)
+	}
+}
+
+// jsonReply converts command reply into value encoded into json.
+func jsonReply(reply command.Reply) interface{} {
+	switch r := reply.(type) {
+	case error:
+		return errorResponse{r.Error()}
+	case command.Status:
+		return string(r)
+	default:
+		return r
+	}
+}
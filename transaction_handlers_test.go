@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTransactionServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/transaction", ExecuteTransaction).Methods(http.MethodPost)
+	return httptest.NewServer(router)
+}
+
+func TestExecuteTransaction(t *testing.T) {
+	Storage.Clear()
+	Storage.RPush("todo", "buy milk", "call Ivan")
+	server := newTransactionServer()
+	defer server.Close()
+
+	body := `{"commands": [["LPOP", "todo"], ["RPUSH", "done", "buy milk"], ["INCR", "done:count"], ["HGET", "todo", "x"], ["SET", "last", "buy milk"]]}`
+	response := doRequest(t, http.MethodPost, server.URL+"/transaction", body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.JSONEq(t, `["buy milk", 1, 1, {"error": "WRONGTYPE Operation against a key holding the wrong kind of value"}, "OK"]`, readBody(t, response))
+
+	done, _ := Storage.LRange("done", 0, -1)
+	assert.Equal(t, []interface{}{"buy milk"}, done)
+}
+
+func TestExecuteTransaction_watch(t *testing.T) {
+	Storage.Clear()
+	Storage.IncrBy("balance", 100, 0)
+	server := newTransactionServer()
+	defer server.Close()
+	version := Storage.Version("balance")
+	body := fmt.Sprintf(`{"watch": {"balance": %d, "limit": 0}, "commands": [["INCRBY", "balance", "-50"]]}`, version)
+
+	Storage.IncrBy("balance", -70, 0)
+	response := doRequest(t, http.MethodPost, server.URL+"/transaction", body)
+	assert.Equal(t, http.StatusPreconditionFailed, response.StatusCode)
+	value, _ := Storage.Get("balance")
+	assert.Equal(t, int64(30), value.(datatype.DataType).Value, "Aborted transaction is not executed")
+
+	body = fmt.Sprintf(`{"watch": {"balance": %d}, "commands": [["INCRBY", "balance", "-20"]]}`, Storage.Version("balance"))
+	response = doRequest(t, http.MethodPost, server.URL+"/transaction", body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var replies []int64
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&replies))
+	assert.Equal(t, []int64{10}, replies)
+}
+
+func TestExecuteTransaction_invalid(t *testing.T) {
+	Storage.Clear()
+	server := newTransactionServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPost, server.URL+"/transaction", `{"commands": [["SET", "name", "Ivan"], ["MOVE", "name"]]}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.JSONEq(t, `{"error": "ERR unknown command 'MOVE'"}`, readBody(t, response))
+	assert.Equal(t, false, Storage.Contains("name"))
+	response = doRequest(t, http.MethodPost, server.URL+"/transaction", `{"commands": []}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = doRequest(t, http.MethodPost, server.URL+"/transaction", `[["SET", "name", "Ivan"]]`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
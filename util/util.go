@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -59,3 +60,78 @@ func RandString(n int) string {
 	}
 	return string(b)
 }
+
+// MatchPattern reports whether str matches glob-style pattern like Redis KEYS command does:
+// '*' matches any sequence of characters, '?' matches single character,
+// '[abc]' and '[a-z]' match character classes ('^' negates class), '\\' escapes next character.
+func MatchPattern(pattern, str string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(str); i++ {
+				if MatchPattern(pattern[1:], str[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(str) == 0 {
+				return false
+			}
+		case '[':
+			if len(str) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 1 {
+				if str[0] != '[' {
+					return false
+				}
+				break
+			}
+			if !matchClass(pattern[1:end+1], str[0]) {
+				return false
+			}
+			pattern = pattern[end+1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(str) == 0 || pattern[0] != str[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		str = str[1:]
+	}
+	return len(str) == 0
+}
+
+// matchClass reports whether character c belongs to character class like "abc", "a-z" or "^0-9".
+func matchClass(class string, c byte) bool {
+	negate := false
+	if class[0] == '^' && len(class) > 1 {
+		negate = true
+		class = class[1:]
+	}
+	matched := false
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			if class[i] <= c && c <= class[i+2] {
+				matched = true
+			}
+			i += 2
+		} else if class[i] == c {
+			matched = true
+		}
+	}
+	return matched != negate
+}
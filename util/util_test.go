@@ -42,6 +42,21 @@ func TestRandString(t *testing.T) {
 	assert.NotEqual(t, RandString(10), RandString(10), "Generated strings should not be same")
 }
 
+func TestMatchPattern(t *testing.T) {
+	assert.Equal(t, true, MatchPattern("*", "items/name"), "Star should match any string")
+	assert.Equal(t, true, MatchPattern("na*", "name"))
+	assert.Equal(t, true, MatchPattern("*me", "name"))
+	assert.Equal(t, true, MatchPattern("n?me", "name"))
+	assert.Equal(t, true, MatchPattern("n[ao]me", "nome"))
+	assert.Equal(t, true, MatchPattern("n[a-c]me", "nbme"))
+	assert.Equal(t, true, MatchPattern("n[^x]me", "name"))
+	assert.Equal(t, true, MatchPattern("n\\*me", "n*me"))
+	assert.Equal(t, false, MatchPattern("n\\*me", "name"))
+	assert.Equal(t, false, MatchPattern("n[^a]me", "name"))
+	assert.Equal(t, false, MatchPattern("n?me", "nme"))
+	assert.Equal(t, false, MatchPattern("na", "name"))
+}
+
 func ExampleContains() {
 	arr := []interface{}{"one", "five"}
 	Contains(arr, "one")
@@ -61,6 +76,10 @@ func ExampleStringListToInterfaceList() {
 	StringListToInterfaceList([]string{"str 1", "str 2"})
 }
 
+func ExampleMatchPattern() {
+	MatchPattern("user:*", "user:42")
+}
+
 func ExampleRandString() {
 	RandString(10)
 }
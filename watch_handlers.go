@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultWatchBuffer is amount of events buffered for each watch stream by default.
+const defaultWatchBuffer = 1024
+
+// maxWatchBuffer limits amount of events buffered for each watch stream, so clients can't make server
+// allocate unbounded memory.
+const maxWatchBuffer = 65536
+
+// WatchKeys streams changes of keys starting with "prefix" query parameter (all keys by default)
+// as Server-Sent Events of set, delete, expire, evict and clear types with json-encoded datastore.Event data.
+// Up to "buffer" query parameter events (at most maxWatchBuffer) are buffered, stream is finished when client disconnects
+// or doesn't keep up with changes, so client should drop its cached values on reconnect.
+func WatchKeys(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	buffer := defaultWatchBuffer
+	if value := query.Get("buffer"); value != "" {
+		var err error
+		if buffer, err = strconv.Atoi(value); err != nil || buffer <= 0 || buffer > maxWatchBuffer {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+	}
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		populateResponseWriter(writer, http.StatusInternalServerError)
+		return
+	}
+
+	watcher := Storage.Watch(query.Get("prefix"), buffer)
+	defer watcher.Close()
+	startEventStream(writer, flusher)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case event, ok := <-watcher.Events():
+			if !ok {
+				return
+			}
+			err = writeEvent(writer, string(event.Type), event)
+		case <-heartbeat.C:
+			_, err = writer.Write([]byte(": heartbeat\n\n"))
+		case <-request.Context().Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newWatchServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/watch", WatchKeys).Methods(http.MethodGet)
+	return httptest.NewServer(router)
+}
+
+func TestWatchKeys(t *testing.T) {
+	Storage.Clear()
+	server := newWatchServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/watch?prefix=user:", "")
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	reader := bufio.NewReader(response.Body)
+	assert.Equal(t, ": subscribed\n", readEvent(t, reader))
+
+	stored := Storage.Set("user:1", datatype.NewString("Ivan", time.Minute))
+	Storage.Set("city", datatype.NewString("Minsk", time.Minute))
+	Storage.Delete("user:1")
+	Storage.Set("user:2", datatype.NewString("Petr", 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	Storage.RemoveExpired(0)
+	Storage.Clear()
+
+	assert.Equal(t, fmt.Sprintf("event: set\ndata: {\"type\":\"set\",\"key\":\"user:1\",\"version\":%d}\n", stored.Version), readEvent(t, reader))
+	assert.Equal(t, "event: delete\ndata: {\"type\":\"delete\",\"key\":\"user:1\"}\n", readEvent(t, reader))
+	readEvent(t, reader)
+	assert.Equal(t, "event: expire\ndata: {\"type\":\"expire\",\"key\":\"user:2\"}\n", readEvent(t, reader))
+	assert.Equal(t, "event: clear\ndata: {\"type\":\"clear\"}\n", readEvent(t, reader))
+}
+
+func TestWatchKeys_slowClient(t *testing.T) {
+	Storage.Clear()
+	server := newWatchServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/watch?buffer=1", "")
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readEvent(t, reader)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+			}
+		}
+	}()
+
+	// stream is finished when buffer is overflowed, so client knows that events were lost
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			break
+		}
+	}
+}
+
+func TestWatchKeys_invalidBuffer(t *testing.T) {
+	server := newWatchServer()
+	defer server.Close()
+
+	for _, buffer := range []string{"0", "-1", "abc", "65537", "2000000000"} {
+		response := doRequest(t, http.MethodGet, server.URL+"/watch?buffer="+buffer, "")
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, buffer)
+	}
+}
@@ -4,70 +4,240 @@ import (
 	"github.com/carlescere/scheduler"
 	"github.com/gorilla/mux"
 	json "github.com/json-iterator/go"
+	"github.com/andrei-punko/go-cache/command"
 	"github.com/andrei-punko/go-cache/datastore"
 	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/andrei-punko/go-cache/persistence"
+	"github.com/andrei-punko/go-cache/pubsub"
+	"github.com/andrei-punko/go-cache/resp"
+	"github.com/andrei-punko/go-cache/telnet"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
 var Storage = datastore.NewDataStore()
 
+// Commands executes commands against Storage, it is shared by HTTP handlers and text protocols.
+var Commands = command.NewExecutor(Storage, 24*time.Hour)
+
+// Broker delivers messages published to channels by HTTP handlers and text protocols.
+var Broker = pubsub.NewBroker(pubsub.DefaultBufferSize)
+
+// Snapshots saves Storage into snapshot file, it is nil when snapshots are disabled.
+var Snapshots *persistence.Snapshotter
+
+// AppendOnlyLog logs all mutations of Storage, it is nil when append-only log is disabled.
+var AppendOnlyLog *persistence.AppendOnlyLog
+
+var (
+	respPort   = flag.String("resp-port", "6379", "port of Redis RESP protocol listener, empty value disables it")
+	telnetPort = flag.String("telnet-port", "2323", "port of plain-text command protocol listener, empty value disables it")
+	defaultTtl = flag.Duration("default-ttl", 24*time.Hour, "TTL of items saved by SET command of RESP/telnet protocols without TTL")
+	maxItems   = flag.Int("max-items", 0, "maximal amount of items in cache, items are evicted above it; 0 means no limit")
+	maxMemory  = flag.Int64("max-memory", 0, "maximal estimated memory usage of cache in bytes, items are evicted above it; 0 means no limit")
+	eviction   = flag.String("eviction-policy", datastore.PolicyLru, "policy which chooses items to evict: lru, lfu, tinylfu, random or volatile-ttl")
+	shards     = flag.Int("shards", datastore.DefaultShards, "amount of independently locked partitions of cache without size limits")
+
+	expireInterval = flag.Duration("expire-interval", time.Second, "interval between background removals of expired items, not positive value disables them")
+
+	snapshotFile     = flag.String("snapshot-file", "", "path of snapshot file, empty value disables snapshots")
+	snapshotInterval = flag.Duration("snapshot-interval", 5*time.Minute, "interval between periodic snapshots, at least 1s, rounded down to whole seconds")
+
+	aofFile           = flag.String("aof-file", "", "path of append-only log file, empty value disables append-only log")
+	aofFsync          = flag.String("aof-fsync", "everysec", "fsync policy of append-only log: always, everysec or no")
+	aofRewriteMinSize = flag.Int64("aof-rewrite-min-size", 64*1024*1024, "minimal size of append-only log in bytes for automatic rewrite")
+	aofRewritePercent = flag.Int("aof-rewrite-percentage", 100, "growth of append-only log since last rewrite in percents which triggers automatic rewrite")
+)
+
 func main() {
+	flag.Parse()
+	policy, err := datastore.EvictionPolicyByName(*eviction, *maxItems)
+	if err != nil {
+		log.Fatal(err)
+	}
+	Storage = datastore.NewDataStore(datastore.WithMaxItems(*maxItems), datastore.WithMaxMemory(*maxMemory),
+		datastore.WithEvictionPolicy(policy), datastore.WithShards(*shards))
+	Commands = command.NewExecutor(Storage, *defaultTtl)
+	Commands.SetBroker(Broker)
 	port := extractPortFromCmdParams()
 	log.Printf("Starting web-cache on port %s ...", port)
-	scheduler.Every(10).Seconds().Run(cleanupExpiredItems)
+	startExpirer()
+	startSnapshots()
+	startAppendOnlyLog()
+	handleShutdown()
+	startRespServer()
+	startTelnetServer()
 
 	router := mux.NewRouter()
+	router.HandleFunc("/stats", ReadStats).Methods(http.MethodGet)
+	router.HandleFunc("/snapshot", SaveSnapshot).Methods(http.MethodPost)
+	router.HandleFunc("/aof/rewrite", RewriteAppendOnlyLog).Methods(http.MethodPost)
 	router.HandleFunc("/items/{key}", CreateItem).Methods(http.MethodPost)
 	router.HandleFunc("/items/keys", ReadKeys).Methods(http.MethodGet)
 	router.HandleFunc("/items/keys", Clear).Methods(http.MethodDelete)
 	router.HandleFunc("/items/{key}", ReadItem).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/ttl", ReadTtl).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/ttl", UpdateTtl).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/ttl", DeleteTtl).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/incr", IncrementItem).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/list", ReadList).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/list/length", ReadListLength).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/list/trim", TrimList).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/list/{end:head|tail}", PushList).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/list/{end:head|tail}", PopList).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/list/{index:-?[0-9]+}", ReadListItem).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/list/{index:-?[0-9]+}", UpdateListItem).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/fields", ReadFields).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/fields/{field}", ReadField).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/fields/{field}", CheckField).Methods(http.MethodHead)
+	router.HandleFunc("/items/{key}/fields/{field}", UpdateField).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/fields/{field}", DeleteField).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/fields/{field}/incr", IncrementField).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/members", ReadMembers).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/members", AddMembers).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/members/{member}", CheckMember).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/items/{key}/members/{member}", AddMember).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/members/{member}", DeleteMember).Methods(http.MethodDelete)
+	router.HandleFunc("/sets/{operation:union|inter|diff}", CombineSets).Methods(http.MethodGet)
+	router.HandleFunc("/sets/{operation:union|inter|diff}", StoreCombinedSets).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/scores", ReadScores).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/scores", AddScores).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/scores/{member}", ReadScore).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/scores/{member}", UpdateScore).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/scores/{member}", DeleteScore).Methods(http.MethodDelete)
+	router.HandleFunc("/items/{key}/scores/{member}/incr", IncrementScore).Methods(http.MethodPost)
+	router.HandleFunc("/items/{key}/scores/{member}/rank", ReadRank).Methods(http.MethodGet)
 	router.HandleFunc("/items/{key}", DeleteItem).Methods(http.MethodDelete)
+	router.HandleFunc("/transaction", ExecuteTransaction).Methods(http.MethodPost)
+	router.HandleFunc("/channels", SubscribeChannels).Methods(http.MethodGet)
+	router.HandleFunc("/channels/{channel}", PublishMessage).Methods(http.MethodPost)
+	router.HandleFunc("/watch", WatchKeys).Methods(http.MethodGet)
 
 	http.ListenAndServe(":"+port, router)
 }
 
 func extractPortFromCmdParams() string {
-	if len(os.Args) == 1 {
+	if flag.NArg() == 0 {
 		return "8000"
 	}
-	return os.Args[1]
+	return flag.Arg(0)
 }
 
-// cleanupExpiredItems removes expired items from storage.
-func cleanupExpiredItems() {
-	keys := Storage.GetKeys()
-	indexForCleanup := determineIndexForCleanup(keys, time.Now())
-	if indexForCleanup != -1 {
-		Storage.BatchDelete(keys[:indexForCleanup+1])
+// startExpirer starts background removal of expired items if it is enabled.
+func startExpirer() {
+	if *expireInterval <= 0 {
+		log.Printf("Background removal of expired items is disabled, they are removed on access only")
 	}
+	Storage.StartExpirer(*expireInterval, datastore.DefaultExpireBatchSize)
 }
 
-// determineIndexForCleanup used binary search to determine rightmost index of expired items.
-func determineIndexForCleanup(keys []interface{}, time time.Time) int {
-	leftIndex := -1
-	rightIndex := len(keys) - 1
-	for rightIndex-leftIndex > 1 {
-		index := (leftIndex + rightIndex) / 2
-		if isBefore(keys[index], time) {
-			leftIndex = index
-		} else {
-			rightIndex = index
+// startSnapshots loads snapshot file into storage and schedules periodic snapshots if they are enabled.
+// Snapshot is not loaded when existing append-only log will be replayed instead.
+func startSnapshots() {
+	if *snapshotFile == "" {
+		return
+	}
+	if err := validateSnapshotInterval(*snapshotInterval); err != nil {
+		log.Fatal(err)
+	}
+	Snapshots = persistence.NewSnapshotter(Storage, *snapshotFile)
+	Commands.SetSaver(Snapshots)
+	if _, err := os.Stat(*aofFile); *aofFile == "" || os.IsNotExist(err) {
+		count, err := Snapshots.Load()
+		if err != nil {
+			log.Fatal(err)
 		}
+		log.Printf("Loaded %d items from snapshot %s", count, *snapshotFile)
 	}
 
-	return leftIndex
+	scheduler.Every(int(snapshotInterval.Seconds())).Seconds().Run(saveSnapshot)
+}
+
+// validateSnapshotInterval checks that interval could be used by scheduler, which runs jobs every whole amount of seconds.
+func validateSnapshotInterval(interval time.Duration) error {
+	if interval < time.Second {
+		return fmt.Errorf("invalid -snapshot-interval %v: it should be at least 1s", interval)
+	}
+	return nil
+}
+
+func saveSnapshot() {
+	if err := Snapshots.Save(); err != nil {
+		log.Printf("Error during snapshot saving: %v", err)
+	}
+}
+
+// startAppendOnlyLog replays append-only log into storage and starts logging of storage mutations if it is enabled.
+func startAppendOnlyLog() {
+	if *aofFile == "" {
+		return
+	}
+	policy, err := persistence.ParseFsyncPolicy(*aofFsync)
+	if err != nil {
+		log.Fatal(err)
+	}
+	AppendOnlyLog, err = persistence.OpenAppendOnlyLog(Storage, *aofFile, policy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	Commands.SetRewriter(AppendOnlyLog)
+	AppendOnlyLog.StartAutoRewrite(10*time.Second, *aofRewriteMinSize, *aofRewritePercent)
+}
+
+// handleShutdown saves snapshot and closes append-only log on SIGINT/SIGTERM before exit.
+func handleShutdown() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		if Snapshots != nil {
+			saveSnapshot()
+		}
+		if AppendOnlyLog != nil {
+			if err := AppendOnlyLog.Close(); err != nil {
+				log.Printf("Error during append-only log closing: %v", err)
+			}
+		}
+		os.Exit(0)
+	}()
+}
+
+// startRespServer starts Redis RESP protocol listener in background if it is enabled.
+func startRespServer() {
+	if *respPort == "" {
+		return
+	}
+	log.Printf("Starting RESP listener on port %s ...", *respPort)
+	server := resp.NewServer(Commands)
+	go func() {
+		log.Fatal(server.ListenAndServe(":" + *respPort))
+	}()
 }
 
-func isBefore(key interface{}, time time.Time) bool {
-	value, _ := Storage.Get(key.(string))
-	dataTypeItem := value.(datatype.DataType)
-	return dataTypeItem.DeathTime.Before(time)
+// startTelnetServer starts plain-text command protocol listener in background if it is enabled.
+func startTelnetServer() {
+	if *telnetPort == "" {
+		return
+	}
+	log.Printf("Starting telnet listener on port %s ...", *telnetPort)
+	server := telnet.NewServer(Commands)
+	go func() {
+		log.Fatal(server.ListenAndServe(":" + *telnetPort))
+	}()
 }
 
-// CreateItem creates item and saves it to storage.
+// CreateItem creates item and saves it to storage. Stored item is returned with its version.
+// Item is saved conditionally when request has header "If-None-Match: *" (only if there is no such item),
+// "If-Match: *" (only if there is such item) or "If-Match: <version>" (only if the item has this version),
+// 412 status code is returned when the condition fails.
 func CreateItem(writer http.ResponseWriter, request *http.Request) {
 	var value datatype.DataType
 	err := json.NewDecoder(request.Body).Decode(&value)
@@ -76,11 +246,33 @@ func CreateItem(writer http.ResponseWriter, request *http.Request) {
 		populateResponseWriter(writer, http.StatusInternalServerError)
 		return
 	}
-	value.DeathTime = time.Now().Add(value.Ttl)
+	value.DeathTime = datatype.DeathTimeOf(value.Ttl)
 
 	vars := mux.Vars(request)
 	key := vars["key"]
-	Storage.Set(key, value)
+	ok := true
+	ifMatch := request.Header.Get("If-Match")
+	switch {
+	case request.Header.Get("If-None-Match") == "*":
+		value, ok = Storage.SetNX(key, value)
+	case ifMatch == "*":
+		value, ok = Storage.SetXX(key, value)
+	case ifMatch != "":
+		version, err := strconv.ParseUint(strings.Trim(strings.TrimSpace(ifMatch), `"`), 10, 64)
+		if err != nil {
+			populateResponseWriter(writer, http.StatusBadRequest)
+			return
+		}
+		value, err = Storage.CompareAndSwap(key, version, value)
+		ok = err == nil
+	default:
+		value = Commands.Set(key, value)
+	}
+	if !ok {
+		populateResponseWriter(writer, http.StatusPreconditionFailed)
+		return
+	}
+	writeValidators(writer, value)
 	resultJson, err := json.Marshal(value)
 	if err != nil {
 		log.Println("Error during json encoding")
@@ -96,11 +288,16 @@ func CreateItem(writer http.ResponseWriter, request *http.Request) {
 func ReadItem(writer http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	key := vars["key"]
-	value, ok := Storage.Get(key)
-	if !ok {
+	value, err := Commands.Get(key)
+	if err == command.ErrNotFound {
 		populateResponseWriter(writer, http.StatusNotFound)
 		return
 	}
+	writeValidators(writer, value)
+	if isNotModified(request, value) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
 
 	resultJson, err := json.Marshal(value)
 	if err != nil {
@@ -113,9 +310,43 @@ func ReadItem(writer http.ResponseWriter, request *http.Request) {
 	writer.Write(resultJson)
 }
 
+// etagOf returns strong entity tag of the item derived from its version.
+func etagOf(item datatype.DataType) string {
+	return `"` + strconv.FormatUint(item.Version, 10) + `"`
+}
+
+// writeValidators sets ETag and Last-Modified headers of the item.
+func writeValidators(writer http.ResponseWriter, item datatype.DataType) {
+	writer.Header().Set("ETag", etagOf(item))
+	if !item.Modified.IsZero() {
+		writer.Header().Set("Last-Modified", item.Modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// isNotModified checks "If-None-Match" header (list of entity tags or "*") against ETag of the item,
+// "If-Modified-Since" header is checked only when there is no "If-None-Match" one.
+func isNotModified(request *http.Request, item datatype.DataType) bool {
+	if header := request.Header.Get("If-None-Match"); header != "" {
+		etag := etagOf(item)
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(request.Header.Get("If-Modified-Since"))
+	if err != nil || item.Modified.IsZero() {
+		return false
+	}
+	// Last-Modified has precision of seconds
+	return !item.Modified.Truncate(time.Second).After(since)
+}
+
 // ReadKeys reads and returns all keys saved in storage.
 func ReadKeys(writer http.ResponseWriter, request *http.Request) {
-	keys := Storage.GetKeys()
+	keys := Commands.Keys("*")
 	resultJson, err := json.Marshal(keys)
 	if err != nil {
 		log.Println("Error during json encoding")
@@ -131,7 +362,7 @@ func ReadKeys(writer http.ResponseWriter, request *http.Request) {
 func DeleteItem(writer http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	key := vars["key"]
-	if ok := Storage.Delete(key); !ok {
+	if err := Commands.Delete(key); err == command.ErrNotFound {
 		populateResponseWriter(writer, http.StatusNotFound)
 		return
 	}
@@ -139,9 +370,135 @@ func DeleteItem(writer http.ResponseWriter, request *http.Request) {
 	populateResponseWriter(writer, http.StatusNoContent)
 }
 
+// ttlRequest is a body of UpdateTtl request, either Ttl or DeathTime should be provided.
+type ttlRequest struct {
+	Ttl       *time.Duration `json:"ttl"`
+	DeathTime *time.Time     `json:"deathTime"`
+}
+
+// ttlResponse is a body of ReadTtl response, Ttl is -1 for items which never expire.
+type ttlResponse struct {
+	Ttl time.Duration `json:"ttl"`
+}
+
+// ReadTtl returns remaining time to live of specified item.
+func ReadTtl(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	ttl, err := Commands.Ttl(vars["key"])
+	if err == command.ErrNotFound {
+		populateResponseWriter(writer, http.StatusNotFound)
+		return
+	}
+
+	resultJson, err := json.Marshal(ttlResponse{ttl})
+	if err != nil {
+		log.Println("Error during json encoding")
+		populateResponseWriter(writer, http.StatusInternalServerError)
+		return
+	}
+
+	populateResponseWriter(writer, http.StatusOK)
+	writer.Write(resultJson)
+}
+
+// UpdateTtl sets new TTL or DeathTime of specified item and returns updated item.
+// Item is removed when its new DeathTime has already passed, in this case nothing is returned.
+func UpdateTtl(writer http.ResponseWriter, request *http.Request) {
+	var body ttlRequest
+	err := json.NewDecoder(request.Body).Decode(&body)
+	if err != nil || (body.Ttl == nil) == (body.DeathTime == nil) {
+		populateResponseWriter(writer, http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(request)
+	var value datatype.DataType
+	if body.Ttl != nil {
+		value, err = Commands.Expire(vars["key"], *body.Ttl)
+	} else {
+		value, err = Commands.ExpireAt(vars["key"], *body.DeathTime)
+	}
+	if err == command.ErrNotFound {
+		populateResponseWriter(writer, http.StatusNotFound)
+		return
+	}
+	if value.IsExpired(time.Now()) {
+		populateResponseWriter(writer, http.StatusNoContent)
+		return
+	}
+	writeJson(writer, http.StatusOK, value)
+}
+
+// DeleteTtl removes TTL of specified item, so it never expires, and returns updated item.
+func DeleteTtl(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	value, err := Commands.Persist(vars["key"])
+	if err == command.ErrNotFound {
+		populateResponseWriter(writer, http.StatusNotFound)
+		return
+	}
+	writeJson(writer, http.StatusOK, value)
+}
+
+// writeJson writes value encoded into json as response body with provided status code.
+func writeJson(writer http.ResponseWriter, statusCode int, value interface{}) {
+	resultJson, err := json.Marshal(value)
+	if err != nil {
+		log.Println("Error during json encoding")
+		populateResponseWriter(writer, http.StatusInternalServerError)
+		return
+	}
+
+	populateResponseWriter(writer, statusCode)
+	writer.Write(resultJson)
+}
+
 // Clear removes all items from storage.
 func Clear(writer http.ResponseWriter, request *http.Request) {
-	Storage.Clear()
+	Commands.Clear()
+	populateResponseWriter(writer, http.StatusNoContent)
+}
+
+// ReadStats returns statistics of storage: amount of items, estimated memory usage and amount of evicted items.
+func ReadStats(writer http.ResponseWriter, request *http.Request) {
+	resultJson, err := json.Marshal(Storage.Stats())
+	if err != nil {
+		log.Println("Error during json encoding")
+		populateResponseWriter(writer, http.StatusInternalServerError)
+		return
+	}
+
+	populateResponseWriter(writer, http.StatusOK)
+	writer.Write(resultJson)
+}
+
+// SaveSnapshot saves snapshot of storage into snapshot file.
+func SaveSnapshot(writer http.ResponseWriter, request *http.Request) {
+	if Snapshots == nil {
+		populateResponseWriter(writer, http.StatusNotImplemented)
+		return
+	}
+	if err := Snapshots.Save(); err != nil {
+		log.Printf("Error during snapshot saving: %v", err)
+		populateResponseWriter(writer, http.StatusInternalServerError)
+		return
+	}
+
+	populateResponseWriter(writer, http.StatusNoContent)
+}
+
+// RewriteAppendOnlyLog compacts append-only log.
+func RewriteAppendOnlyLog(writer http.ResponseWriter, request *http.Request) {
+	if AppendOnlyLog == nil {
+		populateResponseWriter(writer, http.StatusNotImplemented)
+		return
+	}
+	if err := AppendOnlyLog.Rewrite(); err != nil {
+		log.Printf("Error during append-only log rewrite: %v", err)
+		populateResponseWriter(writer, http.StatusInternalServerError)
+		return
+	}
+
 	populateResponseWriter(writer, http.StatusNoContent)
 }
 
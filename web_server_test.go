@@ -3,13 +3,17 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/andrei-punko/go-cache/datastore"
 	"github.com/andrei-punko/go-cache/datatype"
+	"github.com/andrei-punko/go-cache/persistence"
 	"github.com/andrei-punko/go-cache/util"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -51,6 +55,66 @@ func TestCreateItem(t *testing.T) {
 	assert.LessOrEqual(t, (time.Now().Add(dataTypeItem.Ttl).Sub(dataTypeItem.DeathTime)).Seconds(), 0.1)
 }
 
+func TestCreateItem_withoutTtl(t *testing.T) {
+	Storage.Clear()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", CreateItem).Methods(http.MethodPost)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Post(fmt.Sprintf("%s/items/city", server.URL), "application/json", strings.NewReader(`{"value": "Minsk"}`))
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	value, ok := Storage.Get("city")
+	assert.Equal(t, true, ok, "Item without TTL should not be expired")
+	assert.Equal(t, true, value.(datatype.DataType).IsPersistent())
+	assert.Equal(t, 0, Storage.RemoveExpired(0))
+}
+
+func TestCreateItem_conditional(t *testing.T) {
+	Storage.Clear()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", CreateItem).Methods(http.MethodPost)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	post := func(value, header, condition string) *http.Response {
+		request, _ := http.NewRequest(http.MethodPost, server.URL+"/items/lock", strings.NewReader(`{"value": "`+value+`"}`))
+		if header != "" {
+			request.Header.Set(header, condition)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	assert.Equal(t, http.StatusPreconditionFailed, post("owner-1", "If-Match", "*").StatusCode, "Absent item is not updated")
+	response := post("owner-1", "If-None-Match", "*")
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	var created datatype.DataType
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&created))
+	assert.Equal(t, true, created.Version > 0, "Version is returned")
+	assert.Equal(t, http.StatusPreconditionFailed, post("owner-2", "If-None-Match", "*").StatusCode, "Present item is not overwritten")
+
+	response = post("owner-3", "If-Match", fmt.Sprintf(`"%d"`, created.Version))
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	var swapped datatype.DataType
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&swapped))
+	assert.Equal(t, true, swapped.Version > created.Version)
+	assert.Equal(t, http.StatusPreconditionFailed, post("owner-4", "If-Match", fmt.Sprint(created.Version)).StatusCode, "Stale version is rejected")
+	assert.Equal(t, http.StatusBadRequest, post("owner-4", "If-Match", "latest").StatusCode)
+	assert.Equal(t, http.StatusCreated, post("owner-5", "If-Match", "*").StatusCode)
+
+	value, _ := Storage.Get("lock")
+	assert.Equal(t, "owner-5", value.(datatype.DataType).Value)
+}
+
 func TestReadItem(t *testing.T) {
 	Storage.Clear()
 	Storage.Set("weight", datatype.NewString("82.5kg", 2*time.Minute))
@@ -80,6 +144,85 @@ func TestReadItem(t *testing.T) {
 	assert.LessOrEqual(t, (time.Now().Add(decodedObject.Ttl).Sub(decodedObject.DeathTime)).Seconds(), 0.1)
 }
 
+func TestReadItem_sliding(t *testing.T) {
+	Storage.Clear()
+	session := datatype.NewString("token", time.Minute)
+	session.Sliding = true
+	Storage.Set("session", session)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", ReadItem).Methods(http.MethodGet)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	time.Sleep(10 * time.Millisecond)
+
+	response, err := http.Get(fmt.Sprintf("%s/items/session", server.URL))
+	if err != nil {
+		t.Error(err)
+	}
+	var decodedObject datatype.DataType
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&decodedObject))
+	assert.Equal(t, true, decodedObject.Sliding)
+	assert.Equal(t, true, decodedObject.DeathTime.After(session.DeathTime), "DeathTime should be pushed forward on read")
+}
+
+func TestReadItem_conditional(t *testing.T) {
+	Storage.Clear()
+	stored := Storage.Set("phones", datatype.NewList([]interface{}{"Nokia", "Xiaomi"}, time.Minute))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", ReadItem).Methods(http.MethodGet)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	get := func(header, value string) *http.Response {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/items/phones", nil)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	response := get("", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	etag := response.Header.Get("ETag")
+	assert.Equal(t, fmt.Sprintf(`"%d"`, stored.Version), etag)
+	lastModified := response.Header.Get("Last-Modified")
+	assert.Equal(t, stored.Modified.UTC().Format(http.TimeFormat), lastModified)
+
+	response = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, response.StatusCode)
+	assert.Equal(t, "", readBody(t, response), "Unchanged value is not transferred")
+	assert.Equal(t, http.StatusNotModified, get("If-None-Match", `"1", W/`+etag).StatusCode)
+	assert.Equal(t, http.StatusNotModified, get("If-Modified-Since", lastModified).StatusCode)
+	assert.Equal(t, http.StatusOK, get("If-Modified-Since", stored.Modified.Add(-time.Hour).UTC().Format(http.TimeFormat)).StatusCode)
+
+	Storage.RPush("phones", "Samsung")
+	response = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "Changed value is transferred")
+	assert.NotEqual(t, etag, response.Header.Get("ETag"))
+	assert.Equal(t, http.StatusOK, get("If-None-Match", `"1"`).StatusCode)
+}
+
+func TestReadItem_expired(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("weight", datatype.NewString("82.5kg", -time.Second))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}", ReadItem).Methods(http.MethodGet)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Get(fmt.Sprintf("%s/items/weight", server.URL))
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, http.StatusNotFound, response.StatusCode, "Expired item should not be returned")
+}
+
 func TestReadKeys(t *testing.T) {
 	Storage.Clear()
 	Storage.Set("name", datatype.NewString("Ivan", 2*time.Minute))
@@ -109,6 +252,97 @@ func TestReadKeys(t *testing.T) {
 	assert.Equal(t, true, util.ContainsAll(decodedObject, []interface{}{"name", "weight"}))
 }
 
+func newTtlServer() *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/items/{key}/ttl", ReadTtl).Methods(http.MethodGet)
+	router.HandleFunc("/items/{key}/ttl", UpdateTtl).Methods(http.MethodPut)
+	router.HandleFunc("/items/{key}/ttl", DeleteTtl).Methods(http.MethodDelete)
+	return httptest.NewServer(router)
+}
+
+func doRequest(t *testing.T, method, url, body string) *http.Response {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestReadTtl(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	Storage.Set("city", datatype.NewString("Minsk", 0))
+	server := newTtlServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodGet, server.URL+"/items/name/ttl", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var body ttlResponse
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, true, body.Ttl > 59*time.Second && body.Ttl <= time.Minute)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/city/ttl", "")
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, datastore.NoTtl, body.Ttl)
+
+	response = doRequest(t, http.MethodGet, server.URL+"/items/absent/ttl", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestUpdateTtl(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	Storage.Set("weight", datatype.NewString("82.5kg", time.Minute))
+	server := newTtlServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodPut, server.URL+"/items/name/ttl", `{"ttl": 3600000000000}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var item datatype.DataType
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&item))
+	assert.Equal(t, "Ivan", item.Value)
+	assert.Equal(t, time.Hour, item.Ttl.Round(time.Second))
+
+	deathTime := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/name/ttl", `{"deathTime": "`+deathTime.Format(time.RFC3339)+`"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	value, _ := Storage.Get("name")
+	assert.Equal(t, true, deathTime.Equal(value.(datatype.DataType).DeathTime))
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/weight/ttl", `{"ttl": -1}`)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode, "Item with passed DeathTime is removed")
+	assert.Equal(t, false, Storage.Contains("weight"))
+
+	response = doRequest(t, http.MethodPut, server.URL+"/items/absent/ttl", `{"ttl": 1000000000}`)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/name/ttl", `{}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = doRequest(t, http.MethodPut, server.URL+"/items/name/ttl", `not json`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestDeleteTtl(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", time.Minute))
+	server := newTtlServer()
+	defer server.Close()
+
+	response := doRequest(t, http.MethodDelete, server.URL+"/items/name/ttl", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var item datatype.DataType
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&item))
+	assert.Equal(t, true, item.IsPersistent())
+	ttl, _ := Storage.Ttl("name")
+	assert.Equal(t, datastore.NoTtl, ttl)
+
+	response = doRequest(t, http.MethodDelete, server.URL+"/items/absent/ttl", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
 func TestDeleteItem(t *testing.T) {
 	Storage.Clear()
 	Storage.Set("name", datatype.NewString("Ivan", 2*time.Minute))
@@ -154,34 +388,123 @@ func TestClear(t *testing.T) {
 	assert.Equal(t, 0, Storage.Count(), "Storage should be empty")
 }
 
-func Test_determineIndexForCleanup(t *testing.T) {
+func TestReadStats(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", 2*time.Minute))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/stats", ReadStats).Methods(http.MethodGet)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Get(fmt.Sprintf("%s/stats", server.URL))
+
+	if err != nil {
+		t.Error(err)
+	}
+	if response.StatusCode != 200 {
+		t.Errorf("HTTP Status expected: 200, got: %d", response.StatusCode)
+	}
+	var decodedObject datastore.Stats
+	err = json.NewDecoder(response.Body).Decode(&decodedObject)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, 1, decodedObject.Count)
+	assert.Equal(t, true, decodedObject.Memory > 0, "Memory usage should be estimated")
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	Storage.Clear()
+	Storage.Set("name", datatype.NewString("Ivan", 2*time.Minute))
+	file, err := ioutil.TempFile("", "go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	router := mux.NewRouter()
+	router.HandleFunc("/snapshot", SaveSnapshot).Methods(http.MethodPost)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	snapshotUrl := fmt.Sprintf("%s/snapshot", server.URL)
+
+	Snapshots = nil
+	response, err := http.Post(snapshotUrl, "application/json", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, http.StatusNotImplemented, response.StatusCode, "Snapshots are disabled")
+
+	Snapshots = persistence.NewSnapshotter(Storage, file.Name())
+	defer func() { Snapshots = nil }()
+	response, err = http.Post(snapshotUrl, "application/json", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	stat, _ := os.Stat(file.Name())
+	assert.NotEqual(t, int64(0), stat.Size(), "Snapshot should be written")
+}
+
+func TestStartExpirer(t *testing.T) {
+	defer func(interval time.Duration) {
+		*expireInterval = interval
+		Storage.StopExpirer()
+	}(*expireInterval)
+
+	for _, interval := range []time.Duration{0, -time.Second, 10 * time.Millisecond} {
+		*expireInterval = interval
+		assert.NotPanics(t, startExpirer, interval.String())
+	}
 	Storage.Clear()
-	Storage.Set("name1", datatype.NewString("Ivan", 1*time.Second))
-	Storage.Set("name2", datatype.NewString("Ivan", 2*time.Second))
-	Storage.Set("name3", datatype.NewString("Ivan", 3*time.Second))
-	Storage.Set("name4", datatype.NewString("Ivan", 4*time.Second))
-	Storage.Set("name5", datatype.NewString("Ivan", 5*time.Second))
-	Storage.Set("name6", datatype.NewString("Ivan", 6*time.Second))
-	Storage.Set("name7", datatype.NewString("Ivan", 7*time.Second))
-
-	assert.Equal(t, -1, determineIndexForCleanup(Storage.GetKeys(), time.Now()))
-	assert.Equal(t, 1, determineIndexForCleanup(Storage.GetKeys(), time.Now().Add(2500*time.Millisecond)))
-	assert.Equal(t, 2, determineIndexForCleanup(Storage.GetKeys(), time.Now().Add(3500*time.Millisecond)))
+	Storage.Set("session", datatype.NewString("token", time.Millisecond))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(0), Storage.Stats().Memory, "Expired item should be removed by background expirer")
+}
+
+func TestValidateSnapshotInterval(t *testing.T) {
+	assert.Nil(t, validateSnapshotInterval(time.Second))
+	assert.Nil(t, validateSnapshotInterval(5*time.Minute))
+	for _, interval := range []time.Duration{0, -time.Minute, 500 * time.Millisecond} {
+		assert.NotNil(t, validateSnapshotInterval(interval), interval.String())
+	}
 }
 
-func Test_isBefore(t *testing.T) {
+func TestRewriteAppendOnlyLog(t *testing.T) {
 	Storage.Clear()
-	Storage.Set("name1", datatype.NewString("Ivan", 1*time.Second))
-	Storage.Set("name2", datatype.NewString("Ivan", 2*time.Second))
-	Storage.Set("name3", datatype.NewString("Ivan", 3*time.Second))
-	Storage.Set("name4", datatype.NewString("Ivan", 4*time.Second))
-	Storage.Set("name5", datatype.NewString("Ivan", 5*time.Second))
-
-	assert.Equal(t, true, isBefore("name1", time.Now().Add(2500*time.Millisecond)))
-	assert.Equal(t, true, isBefore("name2", time.Now().Add(2500*time.Millisecond)))
-	assert.Equal(t, false, isBefore("name3", time.Now().Add(2500*time.Millisecond)))
-	assert.Equal(t, false, isBefore("name4", time.Now().Add(2500*time.Millisecond)))
-	assert.Equal(t, false, isBefore("name5", time.Now().Add(2500*time.Millisecond)))
+	dir, err := ioutil.TempDir("", "go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/aof/rewrite", RewriteAppendOnlyLog).Methods(http.MethodPost)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	rewriteUrl := fmt.Sprintf("%s/aof/rewrite", server.URL)
+
+	response, err := http.Post(rewriteUrl, "application/json", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, http.StatusNotImplemented, response.StatusCode, "Append-only log is disabled")
+
+	AppendOnlyLog, err = persistence.OpenAppendOnlyLog(Storage, dir+"/cache.aof", persistence.FsyncNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		AppendOnlyLog.Close()
+		AppendOnlyLog = nil
+	}()
+	response, err = http.Post(rewriteUrl, "application/json", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
 }
 
 func ExampleCreateItem() {